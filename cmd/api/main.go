@@ -1,16 +1,25 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/MyFirstGo/internal/app"
 	"github.com/MyFirstGo/internal/db"
+	"github.com/MyFirstGo/internal/domain"
 	"github.com/MyFirstGo/internal/env"
 	"github.com/MyFirstGo/internal/handler"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/MyFirstGo/internal/mailer"
+	mw "github.com/MyFirstGo/internal/middleware"
+	"github.com/MyFirstGo/internal/oidc"
 	"github.com/MyFirstGo/internal/service"
 	"github.com/MyFirstGo/internal/store"
+	"github.com/MyFirstGo/internal/worker"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -30,6 +39,12 @@ func main() {
 		MinioBucket:    env.GetString("MINIO_BUCKET", "avatars"),
 	}
 
+	trustedProxies, err := mw.ParseTrustedProxies(env.GetString("TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg.TrustedProxies = trustedProxies
+
 	db, err := db.New(
 		cfg.Db.Addr,
 		cfg.Db.MaxOpenConns,
@@ -52,7 +67,64 @@ func main() {
 	validator := validator.New()
 	dbStore := store.NewStorage(db)
 	minioStore := store.NewMinioStore(minioClient, "avatars")
-	service := service.NewService(dbStore, *validator, minioStore)
+
+	if err := helper.SetEncryptionKey(env.GetString("DATA_ENCRYPTION_KEY", "")); err != nil {
+		log.Fatal(err)
+	}
+
+	verificationPolicy, err := service.ParseVerificationPolicy(env.GetString("EMAIL_VERIFICATION_POLICY", string(service.VerifyNone)))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	serviceCfg := service.Config{
+		AccessTokenTTL:       env.GetDuration("JWT_ACCESS_TTL", 15*time.Minute),
+		RefreshTokenTTL:      env.GetDuration("JWT_REFRESH_TTL", 30*24*time.Hour),
+		PasswordResetTTL:     env.GetDuration("PASSWORD_RESET_TTL", time.Hour),
+		PasswordResetURL:     env.GetString("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		EmailVerification:    verificationPolicy,
+		EmailVerificationTTL: env.GetDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		EmailVerificationURL: env.GetString("EMAIL_VERIFICATION_URL", "http://localhost:8080/v1/auth/verify"),
+
+		LoginMaxFailures:   env.GetInt("LOGIN_MAX_FAILURES", 5),
+		LoginIPMaxFailures: env.GetInt("LOGIN_IP_MAX_FAILURES", 20),
+		LoginFailureWindow: env.GetDuration("LOGIN_FAILURE_WINDOW", 24*time.Hour),
+		LoginLockoutBase:   env.GetDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+		LoginLockoutMax:    env.GetDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+
+		TOTPIssuer:      env.GetString("TOTP_ISSUER", "NutriTrack"),
+		MFAChallengeTTL: env.GetDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+
+		JWTSigningAlg:  env.GetString("JWT_SIGNING_ALG", domain.SigningAlgEdDSA),
+		JWTKeyRotation: env.GetDuration("JWT_KEY_ROTATION", 30*24*time.Hour),
+
+		DataExportTTL:     env.GetDuration("DATA_EXPORT_TTL", 7*24*time.Hour),
+		DataExportLinkTTL: env.GetDuration("DATA_EXPORT_LINK_TTL", 15*time.Minute),
+
+		AccountDeletionGrace: env.GetDuration("ACCOUNT_DELETION_GRACE", 30*24*time.Hour),
+		RecentLoginWindow:    env.GetDuration("RECENT_LOGIN_WINDOW", 10*time.Minute),
+
+		OIDCProviders: newIdentityProviders(),
+	}
+	service := service.NewService(dbStore, *validator, minioStore, serviceCfg)
+
+	mail, err := newMailer()
+	if err != nil {
+		log.Fatalf("failed to init mailer: %v", err)
+	}
+
+	// Background workers
+	ctx := context.Background()
+
+	// Key JWT harus sudah ada sebelum server menerima request
+	if err := service.SigningKeys.Rotate(ctx); err != nil {
+		log.Fatalf("failed to load signing keys: %v", err)
+	}
+	go worker.NewSigningKeyRotator(service.SigningKeys, env.GetDuration("JWT_KEY_REFRESH_INTERVAL", time.Minute)).Run(ctx)
+	go worker.NewEmailDispatcher(dbStore, mail, env.GetDuration("OUTBOX_INTERVAL", 10*time.Second)).Run(ctx)
+	go worker.NewDataExporter(service.DataExports, env.GetDuration("DATA_EXPORT_INTERVAL", 30*time.Second)).Run(ctx)
+	go worker.NewFoodImporter(service.FoodImports, env.GetDuration("FOOD_IMPORT_INTERVAL", 10*time.Second)).Run(ctx)
+	go worker.NewAccountPurger(service.Users, env.GetDuration("ACCOUNT_PURGE_INTERVAL", time.Hour)).Run(ctx)
 
 	// 2. Init Shared App State
 	appState := &app.Application{
@@ -69,6 +141,7 @@ func main() {
 	authHandler := handler.NewAuthHandler(appState)
 
 	foodHandler := handler.NewFoodHandler(appState)
+	recipeHandler := handler.NewRecipeHandler(appState)
 
 	userHandler := handler.NewUserHandler(appState)
 
@@ -78,13 +151,68 @@ func main() {
 
 	userHealthHandler := handler.NewUserHealthHandler(appState)
 
+	twoFactorHandler := handler.NewTwoFactorHandler(appState)
+	apiKeyHandler := handler.NewAPIKeyHandler(appState)
+	sessionHandler := handler.NewSessionHandler(appState)
+	auditHandler := handler.NewAuditHandler(appState)
+	exportHandler := handler.NewDataExportHandler(appState)
+
 	// 4. Mount Routes
-	mux := mountRoutes(appState, healthHandler, authHandler, foodHandler, userHandler, profileHandler, diaryHandler, userHealthHandler)
+	mux := mountRoutes(appState, healthHandler, authHandler, foodHandler, recipeHandler, userHandler, profileHandler, diaryHandler, userHealthHandler, twoFactorHandler, apiKeyHandler, sessionHandler, auditHandler, exportHandler)
 
 	// 5. Run Server
 	runServer(appState, mux)
 }
 
+// newMailer memakai SMTP jika SMTP_HOST diisi, selain itu email ditulis ke MAIL_FILE (atau stdout)
+func newMailer() (domain.Mailer, error) {
+	if host := env.GetString("SMTP_HOST", ""); host != "" {
+		return mailer.NewSMTPMailer(
+			host,
+			env.GetInt("SMTP_PORT", 587),
+			env.GetString("SMTP_USERNAME", ""),
+			env.GetString("SMTP_PASSWORD", ""),
+			env.GetString("MAIL_FROM", "no-reply@nutritrack.local"),
+		), nil
+	}
+
+	path := env.GetString("MAIL_FILE", "")
+	if path == "" {
+		return mailer.NewFileMailer(os.Stdout), nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return mailer.NewFileMailer(file), nil
+}
+
+// newIdentityProviders membaca provider dari OIDC_PROVIDERS (dipisah koma), konfigurasi
+// tiap provider diambil dari OIDC_<NAMA>_ISSUER, OIDC_<NAMA>_CLIENT_ID, dst.
+func newIdentityProviders() map[string]domain.IdentityProvider {
+	providers := map[string]domain.IdentityProvider{}
+
+	for _, name := range strings.Split(env.GetString("OIDC_PROVIDERS", ""), ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers[name] = oidc.NewProvider(oidc.Config{
+			Issuer:       env.GetString(prefix+"ISSUER", ""),
+			ClientID:     env.GetString(prefix+"CLIENT_ID", ""),
+			ClientSecret: env.GetString(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  env.GetString(prefix+"REDIRECT_URL", "http://localhost:8080/v1/auth/oidc/"+name+"/callback"),
+			Scopes:       strings.Fields(env.GetString(prefix+"SCOPES", "openid email profile")),
+		}, nil)
+	}
+
+	return providers
+}
+
 func runServer(app *app.Application, mux http.Handler) error {
 
 	srv := &http.Server{
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/MyFirstGo/internal/app"
+	"github.com/MyFirstGo/internal/domain"
 	"github.com/MyFirstGo/internal/handler"
 	mw "github.com/MyFirstGo/internal/middleware"
 	"github.com/go-chi/chi/v5"
@@ -12,36 +13,76 @@ import (
 )
 
 func mountRoutes(
-	_ *app.Application,
+	app *app.Application,
 	healthH *handler.HealthHandler,
 	authH *handler.AuthHandler,
 	foodH *handler.FoodHandler,
+	recipeH *handler.RecipeHandler,
 	userH *handler.UserHandler,
 	profileH *handler.ProfileHandler,
 	diaryH *handler.DiaryHandler,
 	userHealthH *handler.UserHealthHandler,
+	twoFactorH *handler.TwoFactorHandler,
+	apiKeyH *handler.APIKeyHandler,
+	sessionH *handler.SessionHandler,
+	auditH *handler.AuditHandler,
+	exportH *handler.DataExportHandler,
 ) http.Handler {
 	r := chi.NewRouter()
 
+	r.Use(middleware.RequestID)
+	r.Use(mw.RealIP(app.Config.TrustedProxies))
+	r.Use(mw.RequestMeta)
+	r.Use(mw.Locale)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
 
+	r.Get("/.well-known/jwks.json", authH.JWKSHandler)
+
 	r.Route("/v1", func(r chi.Router) {
 		r.Get("/health", healthH.HealthCheckHandler)
 
+		// Review katalog hanya untuk nutritionist dan admin. Semua user bisa membuat food
+		// private, aturan siapa boleh mengubah food mana dicek di service.
+		canEditFoods := chi.Chain(mw.AuthMiddleware(app), mw.RequirePermission(domain.PermFoodsWrite))
+		canCreateFoods := chi.Chain(mw.AuthMiddleware(app), mw.RequirePermission(domain.PermFoodsCreate))
+		// Anonim boleh membaca katalog publik, API key harus punya scope foods:read
+		canReadFoods := chi.Chain(mw.OptionalAuth(app), mw.OptionalPermission(domain.PermFoodsRead))
+
 		r.Route("/foods", func(r chi.Router) {
-			r.Get("/", foodH.GetFoodsHandler)
-			r.Post("/", foodH.CreateFoodsHandler)
+			// Tanpa login hanya katalog publik, dengan login termasuk food private milik sendiri
+			r.With(canReadFoods...).Get("/", foodH.GetFoodsHandler)
+			r.With(canCreateFoods...).Post("/", foodH.CreateFoodsHandler)
+			r.With(canReadFoods...).Get("/barcode/{code}", foodH.GetFoodByBarcodeHandler)
 
 			r.Route("/{foodID}", func(r chi.Router) {
-				r.Get("/", foodH.GetFoodByIdHandler)
-				r.Patch("/", foodH.UpdateFoodsHandler)
-				r.Delete("/", foodH.DeleteFoodsHandler)
+				r.With(canReadFoods...).Get("/", foodH.GetFoodByIdHandler)
+				r.With(canCreateFoods...).Patch("/", foodH.UpdateFoodsHandler)
+				r.With(canCreateFoods...).Delete("/", foodH.DeleteFoodsHandler)
+				r.With(canCreateFoods...).Post("/portions", foodH.CreatePortionHandler)
+				r.With(canCreateFoods...).Delete("/portions/{portionID}", foodH.DeletePortionHandler)
+				r.With(canReadFoods...).Get("/revisions", foodH.ListRevisionsHandler)
+				r.With(canReadFoods...).Get("/revisions/diff", foodH.DiffRevisionsHandler)
+				r.With(canReadFoods...).Get("/revisions/{revision}", foodH.GetRevisionHandler)
+				r.With(canCreateFoods...).Post("/revisions/{revision}/rollback", foodH.RollbackHandler)
+				r.With(canCreateFoods...).Post("/submit", foodH.SubmitFoodHandler)
+				r.With(canEditFoods...).Post("/publish", foodH.PublishFoodHandler)
+				r.With(canEditFoods...).Post("/reject", foodH.RejectFoodHandler)
 			})
 		})
 
+		// Resep adalah food, hapus dan submit lewat /foods/{foodID}
+		r.Route("/recipes", func(r chi.Router) {
+			r.With(canCreateFoods...).Post("/", recipeH.CreateRecipeHandler)
+			r.With(canReadFoods...).Get("/{foodID}", recipeH.GetRecipeHandler)
+			r.With(canCreateFoods...).Put("/{foodID}", recipeH.UpdateRecipeHandler)
+		})
+
 		r.Route("/users", func(r chi.Router) {
+			r.Use(mw.AuthMiddleware(app))
+			r.Use(mw.RequirePermission(domain.PermUsersManage))
+
 			r.Get("/", userH.GetUsersHandler)
 			r.Post("/", userH.CreateUserHandler)
 
@@ -49,34 +90,101 @@ func mountRoutes(
 				r.Get("/", userH.GetUserByIdHandler)
 				r.Patch("/", userH.UpdateUserHandler)
 				r.Delete("/", userH.DeleteUserHandler)
+				r.Patch("/role", userH.UpdateUserRoleHandler)
+				r.Post("/unlock", userH.UnlockUserHandler)
+			})
+		})
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(mw.AuthMiddleware(app))
+
+			r.With(mw.RequirePermission(domain.PermAuditRead)).Get("/audit", auditH.GetAuditEventsHandler)
+
+			// Perawatan katalog: duplikat, penggabungan dan import food
+			r.Route("/foods", func(r chi.Router) {
+				r.Use(mw.RequirePermission(domain.PermFoodsAdmin))
+
+				r.Get("/duplicates", foodH.ListDuplicatesHandler)
+				r.Post("/merge", foodH.MergeFoodsHandler)
+				r.Post("/import", foodH.ImportFoodsHandler)
+				r.Get("/import/{importID}", foodH.GetFoodImportHandler)
+				r.Get("/import/{importID}/errors", foodH.ListFoodImportErrorsHandler)
 			})
 		})
 
 		r.Route("/auth", func(r chi.Router) {
 			r.Post("/register", userH.CreateUserHandler)
 			r.Post("/login", authH.LoginHandler)
+			r.Post("/login/mfa", authH.LoginMFAHandler)
+			r.Post("/refresh", authH.RefreshHandler)
+			r.Get("/oidc/{provider}", authH.OIDCStartHandler)
+			r.Get("/oidc/{provider}/callback", authH.OIDCCallbackHandler)
+			r.With(mw.AuthMiddleware(app), mw.RequireSession).Post("/logout", authH.LogoutHandler)
+
+			r.Get("/verify", authH.VerifyEmailHandler)
+			r.Post("/verify/resend", authH.ResendVerificationHandler)
+
+			r.Route("/password", func(r chi.Router) {
+				r.Post("/forgot", authH.ForgotPasswordHandler)
+				r.Post("/reset", authH.ResetPasswordHandler)
+			})
 		})
 
 		r.Group(func(r chi.Router) {
-			r.Use(mw.AuthMiddleware)
+			r.Use(mw.AuthMiddleware(app))
 
 			// User routes
 			r.Route("/me", func(r chi.Router) {
-				r.Get("/", profileH.GetProfileHandler)
-				r.Patch("/", profileH.UpdateProfileHandler)
-				r.Patch("/password", profileH.UpdatePasswordHandler)
-				r.Patch("/avatar", userH.UpdateAvatarHandler)
+				canReadProfile := mw.RequirePermission(domain.PermProfileRead)
+				canReadDiary := mw.RequirePermission(domain.PermDiaryRead)
+				canWriteDiary := mw.RequirePermission(domain.PermDiaryWrite)
+
+				r.With(canReadProfile).Get("/", profileH.GetProfileHandler)
+				r.With(canReadProfile).Get("/tdee", userHealthH.GetHealthSummary)
+
+				// Pengaturan akun tidak bisa diakses lewat API key
+				r.Group(func(r chi.Router) {
+					r.Use(mw.RequireSession)
+
+					r.Patch("/", profileH.UpdateProfileHandler)
+					r.Delete("/", profileH.DeleteAccountHandler)
+					r.Patch("/password", profileH.UpdatePasswordHandler)
+					r.Patch("/avatar", userH.UpdateAvatarHandler)
+
+					r.Route("/2fa", func(r chi.Router) {
+						r.Post("/totp", twoFactorH.EnrollTOTPHandler)
+						r.Post("/totp/confirm", twoFactorH.ConfirmTOTPHandler)
+						r.Delete("/totp", twoFactorH.DisableTOTPHandler)
+						r.Post("/recovery-codes", twoFactorH.RegenerateRecoveryCodesHandler)
+					})
+
+					r.Route("/export", func(r chi.Router) {
+						r.Post("/", exportH.RequestExportHandler)
+						r.Get("/{exportID}", exportH.GetExportHandler)
+					})
 
-				r.Get("/tdee", userHealthH.GetHealthSummary)
+					r.Route("/sessions", func(r chi.Router) {
+						r.Get("/", sessionH.GetSessionsHandler)
+						r.Delete("/", sessionH.RevokeAllSessionsHandler)
+						r.Delete("/{sessionID}", sessionH.RevokeSessionHandler)
+					})
+
+					r.Route("/api-keys", func(r chi.Router) {
+						r.Get("/", apiKeyH.GetAPIKeysHandler)
+						r.Post("/", apiKeyH.CreateAPIKeyHandler)
+						r.Delete("/{keyID}", apiKeyH.RevokeAPIKeyHandler)
+					})
+				})
 
 				r.Route("/diaries", func(r chi.Router) {
-					r.Get("/", diaryH.GetDiariesHandler)
-					r.Post("/", diaryH.CreateLogHandler)
+					r.With(canReadDiary).Get("/", diaryH.GetDiariesHandler)
+					r.With(canReadDiary).Get("/entries", diaryH.ListDiariesHandler)
+					r.With(canWriteDiary).Post("/", diaryH.CreateLogHandler)
 
 					r.Route("/{diaryID}", func(r chi.Router) {
-						r.Get("/", diaryH.GetDiaryHandler)
-						r.Patch("/", diaryH.UpdateLogHandler)
-						r.Delete("/", diaryH.DeleteLogHandler)
+						r.With(canReadDiary).Get("/", diaryH.GetDiaryHandler)
+						r.With(canWriteDiary).Patch("/", diaryH.UpdateLogHandler)
+						r.With(canWriteDiary).Delete("/", diaryH.DeleteLogHandler)
 					})
 				})
 			})
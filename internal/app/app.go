@@ -1,6 +1,8 @@
 package app
 
 import (
+	"net"
+
 	"github.com/MyFirstGo/internal/service"
 	"github.com/MyFirstGo/internal/store"
 	"github.com/go-playground/validator/v10"
@@ -22,6 +24,8 @@ type Config struct {
 	MinioSecretKey string
 	MinioUseSSL    bool
 	MinioBucket    string
+	// Proxy/load balancer yang boleh mengisi X-Forwarded-For
+	TrustedProxies []*net.IPNet
 }
 
 type Application struct {
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+type APIKey struct {
+	ID         int64        `json:"id"`
+	UserID     int64        `json:"user_id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	Scopes     []Permission `json:"scopes"`
+	LastUsedAt *time.Time   `json:"last_used_at"`
+	ExpiresAt  *time.Time   `json:"expires_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+	RevokedAt  *time.Time   `json:"revoked_at,omitempty"`
+	KeyHash    []byte       `json:"-"`
+}
+
+// CreatedAPIKey hanya dikembalikan sekali saat key dibuat, setelah itu key tidak bisa dilihat lagi
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	AuditUserCreate         = "user.create"
+	AuditUserUpdate         = "user.update"
+	AuditUserDelete         = "user.delete"
+	AuditUserDeleteSchedule = "user.deletion_schedule"
+	AuditUserDeleteCancel   = "user.deletion_cancel"
+	AuditUserPurge          = "user.purge"
+	AuditUserRoleChange     = "user.role_change"
+	AuditUserUnlock         = "user.unlock"
+	AuditPasswordChange     = "user.password_change"
+	AuditPasswordReset      = "user.password_reset"
+	AuditTwoFactorEnable    = "user.2fa_enable"
+	AuditTwoFactorDisable   = "user.2fa_disable"
+	AuditAPIKeyCreate       = "api_key.create"
+	AuditAPIKeyRevoke       = "api_key.revoke"
+	AuditDataExport         = "user.data_export"
+	AuditSessionRevoke      = "session.revoke"
+	AuditSessionRevokeAll   = "session.revoke_all"
+	AuditFoodCreate         = "food.create"
+	AuditFoodUpdate         = "food.update"
+	AuditFoodDelete         = "food.delete"
+	AuditFoodSubmit         = "food.submit"
+	AuditFoodPublish        = "food.publish"
+	AuditFoodReject         = "food.reject"
+	AuditFoodRollback       = "food.rollback"
+	AuditFoodMerge          = "food.merge"
+	AuditFoodImport         = "food.import"
+)
+
+const (
+	AuditTargetUser    = "user"
+	AuditTargetFood    = "food"
+	AuditTargetImport  = "food_import"
+	AuditTargetAPIKey  = "api_key"
+	AuditTargetSession = "session"
+)
+
+type AuditEvent struct {
+	ID          int64                  `json:"id"`
+	ActorID     *int64                 `json:"actor_id"`
+	ActorAPIKey *int64                 `json:"actor_api_key_id,omitempty"`
+	Action      string                 `json:"action"`
+	TargetType  string                 `json:"target_type"`
+	TargetID    *int64                 `json:"target_id"`
+	Changes     map[string]AuditChange `json:"changes,omitempty"`
+	IPAddress   *string                `json:"ip_address"`
+	UserAgent   *string                `json:"user_agent"`
+	RequestID   *string                `json:"request_id"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+type AuditChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+type AuditFilter struct {
+	ActorID    *int64
+	TargetType string
+	TargetID   *int64
+	From       *time.Time
+	To         *time.Time
+	Page       PageRequest
+}
+
+// Actor adalah user (dan API key, jika ada) yang sedang melakukan request
+type Actor struct {
+	UserID   int64
+	APIKeyID int64
+}
+
+// RequestMeta adalah informasi request yang ikut dicatat di audit log
+type RequestMeta struct {
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+type auditContextKey int
+
+const (
+	actorKey auditContextKey = iota
+	requestMetaKey
+)
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey).(Actor)
+	return actor, ok
+}
+
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey, meta)
+}
+
+func RequestMetaFrom(ctx context.Context) RequestMeta {
+	meta, _ := ctx.Value(requestMetaKey).(RequestMeta)
+	return meta
+}
@@ -9,26 +9,47 @@ type FoodDiary struct {
 	AmountConsumed float64    `json:"amount_consumed"`
 	ConsumedAt     time.Time  `json:"consumed_at"`
 	MealType       string     `json:"meal_type"`
+	Quantity       *float64   `json:"quantity"`
+	PortionID      *int64     `json:"portion_id"`
+	PortionName    *string    `json:"portion_name,omitempty"`
 	FoodName       *string    `json:"food_name,omitempty"`
+	FoodRevision   *int       `json:"food_revision"`
 	CreatedAt      *time.Time `json:"created_at"`
 	UpdatedAt      *time.Time `json:"updated_at"`
 }
 
+// DiaryCreateInput: jumlah dicatat sebagai AmountConsumed (dalam Unit, default serving_unit food)
+// atau sebagai Quantity x PortionID
 type DiaryCreateInput struct {
 	UserID         int64     `validate:"required"`
 	FoodID         int64     `validate:"required"`
-	AmountConsumed float64   `validate:"required"`
+	AmountConsumed float64   `validate:"required_without=PortionID,excluded_with=PortionID"`
+	Unit           string    `validate:"excluded_with=PortionID"`
+	PortionID      *int64    `validate:"omitempty"`
+	Quantity       *float64  `validate:"required_with=PortionID,excluded_without=PortionID,omitempty,gt=0"`
 	ConsumedAt     time.Time `validate:"required"`
 	MealType       string    `validate:"required"`
 }
 
+// DiaryUpdateInput: AmountConsumed menghapus portion, PortionID/Quantity mengubah entry ke mode portion
 type DiaryUpdateInput struct {
 	ID             int64      `validate:"required"`
-	AmountConsumed *float64   `validate:"omitempty"`
+	AmountConsumed *float64   `validate:"omitempty,excluded_with=PortionID Quantity"`
+	Unit           string     `validate:"excluded_without=AmountConsumed"`
+	PortionID      *int64     `validate:"omitempty"`
+	Quantity       *float64   `validate:"omitempty,gt=0"`
 	ConsumedAt     *time.Time `validate:"omitempty"`
 	MealType       *string    `validate:"omitempty"`
 }
 
+// DiaryFilter membatasi riwayat diary berdasarkan rentang waktu konsumsi [From, To)
+type DiaryFilter struct {
+	UserID int64
+	From   *time.Time
+	To     *time.Time
+	Page   PageRequest
+}
+
 // Summary untuk Dashboard
 type DailySummary struct {
 	TotalCalories float64     `json:"total_calories"`
@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrUnauthorized       = errors.New("email atau password salah")
@@ -10,4 +13,52 @@ var (
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrValidator          = errors.New("invalid request")
 	ErrCannotDelete       = errors.New("resource cannot be deleted due to existing dependencies")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrTokenReused        = errors.New("refresh token has already been used")
+	ErrEmailNotVerified   = errors.New("email has not been verified")
+	ErrAccountLocked      = errors.New("account is temporarily locked due to too many failed login attempts")
+	ErrTooManyAttempts    = errors.New("too many failed login attempts from this address")
+	ErrInvalidMFACode     = errors.New("invalid two-factor authentication code")
+	ErrTOTPEnabled        = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidExpiry      = errors.New("expiry must be in the future")
+	ErrExportInProgress   = errors.New("a data export is already in progress")
+	ErrInvalidBarcode     = errors.New("invalid barcode")
+	ErrBarcodeTaken       = errors.New("barcode is already assigned to another food")
+	ErrDuplicateFoodName  = errors.New("only one display name per locale is allowed, use foods name for English")
+	ErrFoodForbidden      = errors.New("you are not allowed to modify this food")
+	ErrPrivateBarcode     = errors.New("barcodes can only be assigned to public catalog foods")
+	ErrFoodNotPrivate     = errors.New("only private foods can be submitted to the public catalog")
+	ErrFoodNotSubmitted   = errors.New("food has not been submitted to the public catalog")
+	ErrInvalidIngredient  = errors.New("recipe ingredients must be foods you can see and cannot be recipes themselves")
+	ErrPrivateIngredient  = errors.New("public recipes can only use public catalog foods")
+	ErrRecipeComputed     = errors.New("nutrients and serving of a recipe are computed from its ingredients")
+	ErrInvalidPortion     = errors.New("portion does not belong to this food or the food has no weight-based serving")
+	ErrDuplicatePortion   = errors.New("a portion with this name already exists for this food")
+	ErrInvalidMerge       = errors.New("only distinct public or submitted foods that are not recipes can be merged")
+	ErrMergeIngredient    = errors.New("recipe ingredients of a duplicate cannot be converted to the canonical food serving unit")
+	ErrInvalidImport      = errors.New("import file or column mapping is invalid")
+	ErrInvalidUnit        = errors.New("unknown unit")
+	ErrIncompatibleUnit   = errors.New("unit cannot be converted to the food serving unit")
+	ErrDensityRequired    = errors.New("food density is required to convert between mass and volume")
+	ErrInvalidCursor      = errors.New("invalid pagination cursor")
+	ErrUnknownNutrient    = errors.New("unknown nutrient")
+	ErrUnknownProvider    = errors.New("unknown identity provider")
+	ErrDeletionScheduled  = errors.New("account is already scheduled for deletion")
+	ErrReauthRequired     = errors.New("password is required, or log in again with your identity provider")
+	ErrIdentityConflict   = errors.New("an unverified account with this email already exists, verify it before signing in with this provider")
 )
+
+// LockoutError membawa sisa waktu lockout untuk header Retry-After
+type LockoutError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LockoutError) Unwrap() error {
+	return e.Err
+}
@@ -1,29 +1,156 @@
 package domain
 
+import "time"
+
+// Food: ServingUnit adalah satuan massa atau volume dari pkg/converter, atau "serving" untuk resep.
+// Density (g/ml) dibutuhkan untuk konversi antara massa dan volume, misal susu atau minyak.
 type Food struct {
 	ID          int64            `json:"id"`
 	Name        string           `json:"name"`
+	Locale      string           `json:"locale"`
+	Names       []FoodName       `json:"names"`
 	Description string           `json:"description"`
 	ServingSize *float64         `json:"serving_size"`
 	ServingUnit *string          `json:"serving_unit"`
+	Density     *float64         `json:"density"`
 	Nutrients   []NutrientAmount `json:"nutrients"`
+	Barcodes    []string         `json:"barcodes"`
+	Portions    []FoodPortion    `json:"portions"`
+	OwnerID     *int64           `json:"owner_id"`
+	Visibility  string           `json:"visibility"`
+	Revision    int              `json:"revision"`
+	ExternalID  *string          `json:"external_id,omitempty"`
 	CreatedAt   string           `json:"created_at"`
 	UpdatedAt   string           `json:"updated_at"`
 }
 
+const (
+	FoodVisibilityPrivate   = "private"
+	FoodVisibilitySubmitted = "submitted"
+	FoodVisibilityPublic    = "public"
+)
+
+// FoodViewer menentukan food yang boleh dilihat dan diubah: katalog publik, food milik
+// sendiri, dan bagi editor katalog (foods:write) juga food yang diajukan ke publik.
+// UserID 0 berarti pengunjung tanpa login.
+type FoodViewer struct {
+	UserID int64
+	Editor bool
+}
+
+func (v FoodViewer) owns(food *Food) bool {
+	return v.UserID != 0 && food.OwnerID != nil && *food.OwnerID == v.UserID
+}
+
+func (v FoodViewer) CanView(food *Food) bool {
+	switch food.Visibility {
+	case FoodVisibilityPublic:
+		return true
+	case FoodVisibilitySubmitted:
+		return v.Editor || v.owns(food)
+	default:
+		return v.owns(food)
+	}
+}
+
+// CanModify: pemilik hanya boleh mengubah selama food belum publik, setelah itu katalog milik editor
+func (v FoodViewer) CanModify(food *Food) bool {
+	if food.Visibility == FoodVisibilityPrivate {
+		return v.owns(food)
+	}
+
+	return v.Editor || (food.Visibility == FoodVisibilitySubmitted && v.owns(food))
+}
+
+// FoodRevision adalah snapshot nilai gizi food setelah satu perubahan. Barcode, nama lain
+// dan portion tidak ikut diversikan.
+type FoodRevision struct {
+	ID          int64            `json:"id"`
+	FoodID      int64            `json:"food_id"`
+	Revision    int              `json:"revision"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	ServingSize *float64         `json:"serving_size"`
+	ServingUnit *string          `json:"serving_unit"`
+	Density     *float64         `json:"density"`
+	Nutrients   []NutrientAmount `json:"nutrients"`
+	CreatedBy   *int64           `json:"created_by"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// FoodRevisionDiff berisi perubahan dari revisi From ke To. Nutrient dibandingkan per slug
+// dengan key "nutrients.<slug>".
+type FoodRevisionDiff struct {
+	FoodID  int64                  `json:"food_id"`
+	From    int                    `json:"from"`
+	To      int                    `json:"to"`
+	Changes map[string]AuditChange `json:"changes"`
+}
+
+// FoodName adalah nama food dalam bahasa lain, atau alias jika Alias true
+type FoodName struct {
+	Locale string `json:"locale" validate:"required,min=2,max=10"`
+	Name   string `json:"name" validate:"required,max=255"`
+	Alias  bool   `json:"alias"`
+}
+
+// FoodPortion adalah takaran rumah tangga untuk satu food, misal "slice" = 28 g
+type FoodPortion struct {
+	ID    int64   `json:"id"`
+	Name  string  `json:"name" validate:"required,max=50"`
+	Grams float64 `json:"grams" validate:"gt=0"`
+}
+
 type NutrientAmount struct {
 	ID     int64   `json:"id"`
 	Name   string  `json:"name"`
+	Slug   string  `json:"slug"`
 	Unit   string  `json:"unit"`
 	Amount float64 `json:"amount"`
 }
 
+const (
+	NutrientCalories = "calories"
+
+	// Basis jumlah nutrient saat filter & sort: dinormalisasi per 100g atau apa adanya per serving
+	NutrientBasis100g    = "100g"
+	NutrientBasisServing = "serving"
+
+	FoodSortName      = "name"
+	FoodSortRelevance = "relevance"
+)
+
+type Nutrient struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	Unit string `json:"unit"`
+}
+
+// NutrientRange membatasi jumlah satu nutrient, Min/Max nil berarti tanpa batas
+type NutrientRange struct {
+	Slug string
+	Min  *float64
+	Max  *float64
+}
+
+// FoodSort mengurutkan berdasarkan nama, relevansi, atau slug nutrient.
+// Per100kcal mengurutkan berdasarkan rasio nutrient per 100 kcal, misal protein per 100 kcal.
+type FoodSort struct {
+	Field      string
+	Per100kcal bool
+	Desc       bool
+}
+
 type FoodFilter struct {
-	Query       string
-	MinCalories float64
-	MaxCalories float64
-	Limit       int
-	Offset      int
+	Query     string
+	Nutrients []NutrientRange
+	Sort      FoodSort
+	Basis     string `validate:"oneof=100g serving"`
+	Page      PageRequest
+	Viewer    FoodViewer
+	// Visibility membatasi hasil ke satu visibility, misal daftar pengajuan untuk editor
+	Visibility string `validate:"omitempty,oneof=private submitted public"`
 }
 
 type CreateFoodInput struct {
@@ -31,12 +158,18 @@ type CreateFoodInput struct {
 	Description string   `validate:"omitempty"`
 	ServingSize *float64 `validate:"omitempty"`
 	ServingUnit *string  `validate:"omitempty"`
+	Density     *float64 `validate:"omitempty,gt=0"`
 	Nutrients   []struct {
 		ID     int64   `validate:"required"`
 		Name   string  `validate:"required"`
 		Unit   string  `validate:"required"`
 		Amount float64 `validate:"required"`
 	} `validate:"omitempty"`
+	Barcodes []string      `validate:"omitempty,dive,required"`
+	Names    []FoodName    `validate:"omitempty,dive"`
+	Portions []FoodPortion `validate:"omitempty,dive"`
+	// Kosong berarti public untuk editor katalog dan private untuk user biasa
+	Visibility string `validate:"omitempty,oneof=private public"`
 }
 
 type UpdateFoodInput struct {
@@ -44,7 +177,10 @@ type UpdateFoodInput struct {
 	Description *string
 	ServingSize *float64
 	ServingUnit *string
+	Density     *float64 `validate:"omitempty,gt=0"`
 	Nutrients   *[]UpdateNutrientInput
+	Barcodes    *[]string   `validate:"omitempty,dive,required"`
+	Names       *[]FoodName `validate:"omitempty,dive"`
 }
 
 type UpdateNutrientInput struct {
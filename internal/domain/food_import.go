@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+const (
+	FoodImportCSV    = "csv"
+	FoodImportNDJSON = "ndjson"
+
+	FoodImportPending    = "pending"
+	FoodImportProcessing = "processing"
+	FoodImportCompleted  = "completed"
+	FoodImportFailed     = "failed"
+)
+
+// FoodImport adalah job import food katalog dari file CSV atau NDJSON. Mapping memetakan
+// nama kolom (atau key JSON) ke field food, misal "food": "name" atau
+// "Caloric Value": "nutrients.calories". Kolom yang tidak dipetakan dicocokkan otomatis
+// dengan nama field atau nama/slug nutrient, sisanya diabaikan.
+type FoodImport struct {
+	ID            int64             `json:"id"`
+	CreatedBy     *int64            `json:"created_by"`
+	Status        string            `json:"status"`
+	Format        string            `json:"format"`
+	DryRun        bool              `json:"dry_run"`
+	Mapping       map[string]string `json:"mapping"`
+	ObjectKey     *string           `json:"-"`
+	TotalRows     *int              `json:"total_rows"`
+	ProcessedRows int               `json:"processed_rows"`
+	CreatedRows   int               `json:"created_rows"`
+	UpdatedRows   int               `json:"updated_rows"`
+	FailedRows    int               `json:"failed_rows"`
+	// Progress dalam persen, nil selama jumlah baris belum dihitung
+	Progress    *float64   `json:"progress"`
+	LastError   *string    `json:"last_error"`
+	Attempts    int        `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// FoodImportInput: File harus bisa di-seek karena header CSV diperiksa sebelum file diunggah
+type FoodImportInput struct {
+	Format  string `validate:"required,oneof=csv ndjson"`
+	DryRun  bool
+	Mapping map[string]string `validate:"omitempty,dive,keys,required,endkeys"`
+	File    io.ReadSeeker     `validate:"required"`
+	Size    int64             `validate:"gt=0"`
+}
+
+// FoodImportRow adalah satu baris file yang sudah dipetakan. Food.ID 0 berarti food baru,
+// Error terisi jika baris gagal dan tidak disimpan.
+type FoodImportRow struct {
+	Row   int
+	Key   string
+	Food  *Food
+	Error string
+}
+
+type FoodImportError struct {
+	Row     int    `json:"row"`
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+type FoodImportErrorFilter struct {
+	ImportID int64
+	Limit    int `validate:"gte=1,lte=500"`
+	Offset   int `validate:"gte=0"`
+}
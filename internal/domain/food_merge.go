@@ -0,0 +1,30 @@
+package domain
+
+// FoodDuplicate adalah pasangan food katalog yang kemungkinan sama. NutrientDistance 0 berarti
+// nutrient per 100g identik dan 1 berarti sama sekali berbeda, nil jika berat serving
+// salah satu food tidak diketahui.
+type FoodDuplicate struct {
+	Food             *Food    `json:"food"`
+	Duplicate        *Food    `json:"duplicate"`
+	NameSimilarity   float64  `json:"name_similarity"`
+	NutrientDistance *float64 `json:"nutrient_distance"`
+}
+
+type FoodDuplicateFilter struct {
+	MinSimilarity float64 `validate:"gte=0,lte=1"`
+	MaxDistance   float64 `validate:"gte=0,lte=1"`
+	Limit         int     `validate:"gte=1,lte=100"`
+}
+
+// FoodMergeInput menggabungkan DuplicateIDs ke CanonicalID
+type FoodMergeInput struct {
+	CanonicalID  int64   `validate:"required"`
+	DuplicateIDs []int64 `validate:"required,min=1,max=50,dive,required"`
+}
+
+type FoodMergeResult struct {
+	Food         *Food   `json:"food"`
+	Merged       []int64 `json:"merged"`
+	DiaryEntries int64   `json:"diary_entries"`
+	Aliases      int64   `json:"aliases"`
+}
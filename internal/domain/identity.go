@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdentityProvider adalah penyedia login eksternal (OpenID Connect)
+type IdentityProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error)
+}
+
+// ExternalIdentity adalah data user dari ID token yang sudah diverifikasi
+type ExternalIdentity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type UserIdentity struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	Provider    string     `json:"provider"`
+	Subject     string     `json:"-"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+// OIDCAuthorization adalah URL login provider beserta state yang harus dicocokkan saat callback
+type OIDCAuthorization struct {
+	URL   string
+	State string
+}
+
+// OIDCAuthRequest menyimpan state login yang sedang berjalan sampai callback dari provider
+type OIDCAuthRequest struct {
+	StateHash    []byte
+	Provider     string
+	CodeVerifier []byte
+	Nonce        string
+	ExpiresAt    time.Time
+}
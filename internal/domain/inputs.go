@@ -14,10 +14,75 @@ type UserCreateInput struct {
 }
 
 type UserLoginInput struct {
-	Email    string `validate:"required,email"`
+	Email     string `validate:"required,email"`
+	Password  string `validate:"required,min=8"`
+	UserAgent string
+	IPAddress string
+}
+
+type UserRoleInput struct {
+	Role Role `validate:"required,oneof=user nutritionist admin"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `validate:"required,email"`
+}
+
+type ResendVerificationInput struct {
+	Email string `validate:"required,email"`
+}
+
+type VerifyEmailInput struct {
+	Token string `validate:"required"`
+}
+
+type ResetPasswordInput struct {
+	Token    string `validate:"required"`
 	Password string `validate:"required,min=8"`
 }
 
+type MFALoginInput struct {
+	ChallengeToken string `validate:"required"`
+	Code           string `validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode   string `validate:"required_without=Code"`
+	UserAgent      string
+	IPAddress      string
+}
+
+type TOTPCodeInput struct {
+	Code string `validate:"required,len=6,numeric"`
+}
+
+type DisableTOTPInput struct {
+	Password string `validate:"required"`
+	Code     string `validate:"required,len=6,numeric"`
+}
+
+// DeleteAccountInput: Password boleh kosong untuk akun dengan identity provider jika
+// session SessionID baru saja login
+type DeleteAccountInput struct {
+	Password  string
+	SessionID int64
+}
+
+type CreateAPIKeyInput struct {
+	Name      string       `validate:"required,max=100"`
+	Scopes    []Permission `validate:"required,min=1,dive,oneof=foods:read foods:create diary:read diary:write profile:read"`
+	ExpiresAt *time.Time   `validate:"omitempty"`
+}
+
+type OIDCCallbackInput struct {
+	Provider  string `validate:"required"`
+	Code      string `validate:"required"`
+	State     string `validate:"required"`
+	UserAgent string
+	IPAddress string
+}
+
+type RefreshTokenInput struct {
+	RefreshToken string `validate:"required"`
+}
+
 type UserUpdateInput struct {
 	Username      *string    `validate:"omitempty,min=3,max=30"`
 	Email         *string    `validate:"omitempty,email"`
@@ -0,0 +1,18 @@
+package domain
+
+import "context"
+
+// DefaultLocale adalah bahasa katalog asli (foods.name), dipakai sebagai fallback
+const DefaultLocale = "en"
+
+type localeContextKey struct{}
+
+// WithLocales menyimpan bahasa yang diminta client, urut dari prioritas tertinggi
+func WithLocales(ctx context.Context, locales []string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locales)
+}
+
+func LocalesFrom(ctx context.Context) []string {
+	locales, _ := ctx.Value(localeContextKey{}).([]string)
+	return locales
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer adalah backend pengirim email (SMTP, file, dll)
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
+
+type OutboxEmail struct {
+	ID        int64
+	Email     Email
+	Attempts  int
+	CreatedAt time.Time
+}
@@ -0,0 +1,39 @@
+package domain
+
+// ServingUnitServing dipakai resep dengan yield jumlah porsi, jumlah di diary berarti jumlah porsi
+const ServingUnitServing = "serving"
+
+// Recipe adalah food yang nutrient-nya dihitung dari bahan. Yield berupa jumlah porsi
+// atau berat matang (gram), salah satu saja.
+type Recipe struct {
+	*Food
+	YieldServings *float64           `json:"yield_servings"`
+	YieldWeight   *float64           `json:"yield_weight"`
+	Ingredients   []RecipeIngredient `json:"ingredients"`
+}
+
+// RecipeIngredient: Quantity dalam Unit, dikonversi ke serving_unit bahan saat nutrient dihitung
+type RecipeIngredient struct {
+	FoodID   int64   `json:"food_id"`
+	FoodName string  `json:"food_name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+}
+
+type RecipeInput struct {
+	Name          string                  `validate:"required,max=255"`
+	Description   string                  `validate:"omitempty"`
+	YieldServings *float64                `validate:"required_without=YieldWeight,excluded_with=YieldWeight,omitempty,gt=0"`
+	YieldWeight   *float64                `validate:"omitempty,gt=0"`
+	Ingredients   []RecipeIngredientInput `validate:"required,min=1,max=50,dive"`
+	Names         []FoodName              `validate:"omitempty,dive"`
+	// Kosong berarti private, resep publik hanya untuk editor katalog
+	Visibility string `validate:"omitempty,oneof=private public"`
+}
+
+// RecipeIngredientInput: Quantity dalam Unit, kosong berarti serving_unit food bahan
+type RecipeIngredientInput struct {
+	FoodID   int64   `validate:"required"`
+	Quantity float64 `validate:"gt=0"`
+	Unit     string
+}
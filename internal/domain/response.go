@@ -13,9 +13,43 @@ type UserResponse struct {
 	DateOfBirth   *time.Time `json:"date_of_birth"`
 	ActivityLevel *int       `json:"activity_level"`
 	Gender        *string    `json:"gender"`
+	Role          Role       `json:"role"`
+	EmailVerified bool       `json:"email_verified"`
+	TwoFactor     bool       `json:"two_factor_enabled"`
 }
 
+// LoginResponse berisi access token, atau challenge token jika user memakai 2FA
 type LoginResponse struct {
-	Token string `json:"token"`
-	Type  string `json:"type"`
+	Token          string `json:"token,omitempty"`
+	RefreshToken   string `json:"refresh_token,omitempty"`
+	Type           string `json:"type"`
+	ExpiresIn      int64  `json:"expires_in"`
+	MFARequired    bool   `json:"mfa_required,omitempty"`
+	ChallengeToken string `json:"challenge_token,omitempty"`
+}
+
+// Cursor menandai posisi di list berupa nilai kolom urutan dari item terakhir
+// (atau item pertama jika Backward, untuk halaman sebelumnya)
+type Cursor struct {
+	Keys     []string `json:"k"`
+	Backward bool     `json:"b,omitempty"`
+}
+
+// PageRequest adalah parameter paginasi keyset yang dibaca dari query string
+type PageRequest struct {
+	Cursor    *Cursor
+	Limit     int
+	WithTotal bool
+}
+
+// Page adalah bentuk respons semua endpoint list. Next dan Prev berisi link ke halaman
+// berikutnya/sebelumnya, Total hanya diisi jika diminta lewat ?total=true.
+type Page[T any] struct {
+	Data  []T     `json:"data"`
+	Next  *string `json:"next"`
+	Prev  *string `json:"prev"`
+	Total *int64  `json:"total,omitempty"`
+
+	NextCursor *Cursor `json:"-"`
+	PrevCursor *Cursor `json:"-"`
 }
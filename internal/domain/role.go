@@ -0,0 +1,42 @@
+package domain
+
+type Role string
+
+const (
+	RoleUser         Role = "user"
+	RoleNutritionist Role = "nutritionist"
+	RoleAdmin        Role = "admin"
+)
+
+type Permission string
+
+const (
+	PermFoodsRead   Permission = "foods:read"
+	PermFoodsWrite  Permission = "foods:write"
+	PermFoodsCreate Permission = "foods:create"
+	PermDiaryRead   Permission = "diary:read"
+	PermDiaryWrite  Permission = "diary:write"
+	PermProfileRead Permission = "profile:read"
+	PermUsersManage Permission = "users:manage"
+	PermAuditRead   Permission = "audit:read"
+	PermFoodsAdmin  Permission = "foods:admin"
+)
+
+// Permission dasar yang dimiliki semua user. foods:create untuk food private milik sendiri,
+// katalog publik tetap butuh foods:write.
+var selfServicePermissions = []Permission{PermFoodsRead, PermFoodsCreate, PermDiaryRead, PermDiaryWrite, PermProfileRead}
+
+var rolePermissions = map[Role][]Permission{
+	RoleUser:         selfServicePermissions,
+	RoleNutritionist: append([]Permission{PermFoodsWrite}, selfServicePermissions...),
+	RoleAdmin:        append([]Permission{PermFoodsWrite, PermFoodsAdmin, PermUsersManage, PermAuditRead}, selfServicePermissions...),
+}
+
+func (r Role) Can(p Permission) bool {
+	for _, perm := range rolePermissions[r] {
+		if perm == p {
+			return true
+		}
+	}
+	return false
+}
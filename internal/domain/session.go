@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+type Session struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	UserAgent  *string    `json:"user_agent"`
+	IPAddress  *string    `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+
+	// Diisi service saat ditampilkan ke user, bukan kolom database
+	Device  string `json:"device"`
+	Current bool   `json:"current"`
+}
+
+type RefreshToken struct {
+	ID        int64
+	SessionID int64
+	TokenHash []byte
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
+
+// TokenClaims adalah identitas request yang sudah tervalidasi, baik dari
+// access token maupun API key (APIKeyID terisi dan akses dibatasi Scopes).
+type TokenClaims struct {
+	UserID    int64
+	SessionID int64
+	Role      Role
+	TokenID   string
+	ExpiresAt time.Time
+	APIKeyID  int64
+	Scopes    []Permission
+}
+
+func (c *TokenClaims) IsAPIKey() bool {
+	return c.APIKeyID != 0
+}
+
+func (c *TokenClaims) Can(p Permission) bool {
+	if !c.Role.Can(p) {
+		return false
+	}
+
+	if !c.IsAPIKey() {
+		return true
+	}
+
+	for _, scope := range c.Scopes {
+		if scope == p {
+			return true
+		}
+	}
+	return false
+}
@@ -3,8 +3,15 @@ package domain
 import (
 	"context"
 	"io"
+	"time"
 )
 
 type FileStorage interface {
 	Upload(ctx context.Context, fileName string, content io.Reader, size int64, contentType string) (string, error)
+	Download(ctx context.Context, fileName string) (io.ReadCloser, error)
+	Delete(ctx context.Context, fileName string) error
+	// DeletePrefix menghapus semua object yang namanya diawali prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+	// PresignedURL membuat link download sementara tanpa perlu autentikasi
+	PresignedURL(ctx context.Context, fileName string, expiry time.Duration) (string, error)
 }
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// TOTPSecret adalah state 2FA user, Secret masih terenkripsi
+type TOTPSecret struct {
+	Secret    []byte
+	EnabledAt *time.Time
+	LastStep  *int64
+}
+
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
@@ -5,17 +5,22 @@ import (
 )
 
 type User struct {
-	ID            int64      `json:"id"`
-	Username      string     `json:"username"`
-	Email         string     `json:"email"`
-	Password      string     `json:"-"`
-	Height        *float64   `json:"height"`
-	Weight        *float64   `json:"weight"`
-	DateOfBirth   *time.Time `json:"date_of_birth"`
-	ActivityLevel *int       `json:"activity_level"`
-	Gender        *string    `json:"gender"`
-	CreatedAt     string     `json:"created_at"`
-	UpdatedAt     string     `json:"updated_at"`
+	ID              int64      `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	Password        string     `json:"-"`
+	Height          *float64   `json:"height"`
+	Weight          *float64   `json:"weight"`
+	DateOfBirth     *time.Time `json:"date_of_birth"`
+	ActivityLevel   *int       `json:"activity_level"`
+	Gender          *string    `json:"gender"`
+	Role            Role       `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	TOTPEnabledAt   *time.Time `json:"totp_enabled_at"`
+	// Waktu akun dijadwalkan untuk dihapus permanen, nil jika tidak ada jadwal
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	CreatedAt           string     `json:"created_at"`
+	UpdatedAt           string     `json:"updated_at"`
 }
 
 func (u *User) GetAge() int {
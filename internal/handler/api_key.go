@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MyFirstGo/internal/app"
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/middleware"
+	"github.com/MyFirstGo/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type APIKeyHandler struct {
+	App *app.Application
+}
+
+func NewAPIKeyHandler(app *app.Application) *APIKeyHandler {
+	return &APIKeyHandler{App: app}
+}
+
+func (h *APIKeyHandler) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	var payload struct {
+		Name      string              `json:"name"`
+		Scopes    []domain.Permission `json:"scopes"`
+		ExpiresAt *time.Time          `json:"expires_at"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	input := domain.CreateAPIKeyInput{
+		Name:      payload.Name,
+		Scopes:    payload.Scopes,
+		ExpiresAt: payload.ExpiresAt,
+	}
+
+	key, err := h.App.Service.APIKeys.Create(r.Context(), userID, input)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			h.App.ValidationErrorResponse(w, r, err)
+		case errors.Is(err, domain.ErrInvalidExpiry):
+			h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusCreated, key, nil)
+}
+
+func (h *APIKeyHandler) GetAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	keys, err := h.App.Service.APIKeys.List(r.Context(), userID)
+	if err != nil {
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, keys, nil)
+}
+
+func (h *APIKeyHandler) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	keyID, err := strconv.ParseInt(chi.URLParam(r, "keyID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := h.App.Service.APIKeys.Revoke(r.Context(), userID, keyID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.App.NotFoundResponse(w, r)
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MyFirstGo/internal/app"
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+)
+
+type AuditHandler struct {
+	App *app.Application
+}
+
+func NewAuditHandler(app *app.Application) *AuditHandler {
+	return &AuditHandler{App: app}
+}
+
+// GetAuditEventsHandler mendukung filter actor_id, target_type, target_id, from dan to (RFC3339),
+// paginasi lewat ?cursor= dari link next/prev
+func (h *AuditHandler) GetAuditEventsHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	page, err := helper.ReadPageRequest(r, 50)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	filter := domain.AuditFilter{
+		TargetType: q.Get("target_type"),
+		Page:       page,
+	}
+
+	if actor := q.Get("actor_id"); actor != "" {
+		actorID, err := strconv.ParseInt(actor, 10, 64)
+		if err != nil {
+			h.App.BadRequestResponse(w, r, fmt.Errorf("invalid actor_id"))
+			return
+		}
+		filter.ActorID = &actorID
+	}
+
+	if target := q.Get("target_id"); target != "" {
+		targetID, err := strconv.ParseInt(target, 10, 64)
+		if err != nil {
+			h.App.BadRequestResponse(w, r, fmt.Errorf("invalid target_id"))
+			return
+		}
+		filter.TargetID = &targetID
+	}
+
+	if filter.From, err = parseTimeQuery(r, "from"); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if filter.To, err = parseTimeQuery(r, "to"); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	events, err := h.App.Service.Audit.List(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	helper.SetPageLinks(r, events)
+
+	h.App.WriteJSON(w, http.StatusOK, events, nil)
+}
+
+func parseTimeQuery(r *http.Request, param string) (*time.Time, error) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, expected RFC3339", param)
+	}
+
+	return &t, nil
+}
@@ -3,11 +3,16 @@ package handler
 import (
 	"errors"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/MyFirstGo/internal/app"
 	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/MyFirstGo/internal/middleware"
 	"github.com/MyFirstGo/internal/store"
+	"github.com/go-playground/validator/v10"
 )
 
 type AuthHandler struct {
@@ -32,17 +37,24 @@ func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	input := domain.UserLoginInput{
-		Email:    payload.Email,
-		Password: payload.Password,
+		Email:     payload.Email,
+		Password:  payload.Password,
+		UserAgent: r.UserAgent(),
+		IPAddress: helper.ClientIP(r),
 	}
 
 	res, err := h.App.Service.Auth.Login(ctx, input)
 	if err != nil {
+		var lockoutErr *domain.LockoutError
 		switch {
+		case errors.As(err, &lockoutErr):
+			h.lockoutResponse(w, lockoutErr)
 		case errors.Is(err, store.ErrNotFound):
 			h.App.WriteJSON(w, http.StatusUnauthorized, "email atau password salah", nil)
 		case errors.Is(err, domain.ErrInvalidCredentials):
 			h.App.WriteJSON(w, http.StatusUnauthorized, "email atau password salah", nil)
+		case errors.Is(err, domain.ErrEmailNotVerified):
+			h.App.ErrorResponse(w, r, http.StatusForbidden, err.Error())
 		default:
 			log.Printf("Database error in LoginHandler: %v", err)
 			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -52,3 +64,223 @@ func (h *AuthHandler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	// Success response
 	h.App.WriteJSON(w, http.StatusOK, res, nil)
 }
+
+func (h *AuthHandler) LoginMFAHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		ChallengeToken string `json:"challenge_token"`
+		Code           string `json:"code"`
+		RecoveryCode   string `json:"recovery_code"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	input := domain.MFALoginInput{
+		ChallengeToken: payload.ChallengeToken,
+		Code:           payload.Code,
+		RecoveryCode:   payload.RecoveryCode,
+		UserAgent:      r.UserAgent(),
+		IPAddress:      helper.ClientIP(r),
+	}
+
+	res, err := h.App.Service.Auth.LoginMFA(r.Context(), input)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		var lockoutErr *domain.LockoutError
+		switch {
+		case errors.As(err, &validationErrors):
+			h.App.ValidationErrorResponse(w, r, err)
+		case errors.As(err, &lockoutErr):
+			h.lockoutResponse(w, lockoutErr)
+		case errors.Is(err, domain.ErrInvalidToken), errors.Is(err, domain.ErrInvalidMFACode):
+			h.App.ErrorResponse(w, r, http.StatusUnauthorized, err.Error())
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, res, nil)
+}
+
+// lockoutResponse mengirim 429 untuk blokir per IP dan 423 untuk akun yang terkunci
+func (h *AuthHandler) lockoutResponse(w http.ResponseWriter, lockoutErr *domain.LockoutError) {
+	retryAfter := int(math.Ceil(lockoutErr.RetryAfter.Seconds()))
+	headers := http.Header{"Retry-After": []string{strconv.Itoa(retryAfter)}}
+
+	status := http.StatusLocked
+	if errors.Is(lockoutErr, domain.ErrTooManyAttempts) {
+		status = http.StatusTooManyRequests
+	}
+
+	h.App.WriteJSON(w, status, map[string]any{
+		"error":       lockoutErr.Error(),
+		"retry_after": retryAfter,
+	}, headers)
+}
+
+func (h *AuthHandler) RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	input := domain.RefreshTokenInput{
+		RefreshToken: payload.RefreshToken,
+	}
+
+	res, err := h.App.Service.Auth.Refresh(r.Context(), input)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			h.App.ValidationErrorResponse(w, r, err)
+		case errors.Is(err, domain.ErrInvalidToken):
+			h.App.ErrorResponse(w, r, http.StatusUnauthorized, err.Error())
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, res, nil)
+}
+
+func (h *AuthHandler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Email string `json:"email"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	input := domain.ForgotPasswordInput{
+		Email: payload.Email,
+	}
+
+	if err := h.App.Service.Auth.ForgotPassword(r.Context(), input); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			h.App.ValidationErrorResponse(w, r, err)
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	// Selalu 202 baik email terdaftar maupun tidak
+	h.App.WriteJSON(w, http.StatusAccepted, map[string]string{
+		"message": "jika email terdaftar, link reset password akan dikirim",
+	}, nil)
+}
+
+func (h *AuthHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	input := domain.ResetPasswordInput{
+		Token:    payload.Token,
+		Password: payload.Password,
+	}
+
+	if err := h.App.Service.Auth.ResetPassword(r.Context(), input); err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			h.App.ValidationErrorResponse(w, r, err)
+		case errors.Is(err, domain.ErrInvalidToken):
+			h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	input := domain.VerifyEmailInput{
+		Token: r.URL.Query().Get("token"),
+	}
+
+	if err := h.App.Service.Auth.VerifyEmail(r.Context(), input); err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			h.App.ValidationErrorResponse(w, r, err)
+		case errors.Is(err, domain.ErrInvalidToken):
+			h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "email berhasil diverifikasi",
+	}, nil)
+}
+
+func (h *AuthHandler) ResendVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Email string `json:"email"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	input := domain.ResendVerificationInput{
+		Email: payload.Email,
+	}
+
+	if err := h.App.Service.Auth.ResendVerification(r.Context(), input); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			h.App.ValidationErrorResponse(w, r, err)
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusAccepted, map[string]string{
+		"message": "jika email terdaftar dan belum diverifikasi, link verifikasi akan dikirim",
+	}, nil)
+}
+
+func (h *AuthHandler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.ClaimsKey).(*domain.TokenClaims)
+
+	if err := h.App.Service.Auth.Logout(r.Context(), claims); err != nil {
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JWKSHandler mempublikasikan public key JWT supaya service lain bisa memverifikasi token kita
+func (h *AuthHandler) JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	headers := http.Header{}
+	headers.Set("Cache-Control", "public, max-age=300")
+
+	h.App.WriteJSON(w, http.StatusOK, h.App.Service.SigningKeys.JWKS(), headers)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/MyFirstGo/internal/app"
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/middleware"
+	"github.com/MyFirstGo/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+type DataExportHandler struct {
+	App *app.Application
+}
+
+func NewDataExportHandler(app *app.Application) *DataExportHandler {
+	return &DataExportHandler{App: app}
+}
+
+func (h *DataExportHandler) RequestExportHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	export, err := h.App.Service.DataExports.Request(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrExportInProgress) {
+			h.App.ErrorResponse(w, r, http.StatusConflict, err.Error())
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusAccepted, export, nil)
+}
+
+func (h *DataExportHandler) GetExportHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	exportID, err := strconv.ParseInt(chi.URLParam(r, "exportID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	export, err := h.App.Service.DataExports.Get(r.Context(), userID, exportID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.App.NotFoundResponse(w, r)
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, export, nil)
+}
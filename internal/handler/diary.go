@@ -9,6 +9,7 @@ import (
 
 	"github.com/MyFirstGo/internal/app"
 	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
 	"github.com/MyFirstGo/internal/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
@@ -46,6 +47,46 @@ func (h *DiaryHandler) GetDiariesHandler(w http.ResponseWriter, r *http.Request)
 	h.App.WriteJSON(w, http.StatusOK, entries, nil)
 }
 
+// ListDiariesHandler mengembalikan riwayat diary dari yang terbaru, bisa dibatasi ?from= dan ?to=
+func (h *DiaryHandler) ListDiariesHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	page, err := helper.ReadPageRequest(r, 10)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	filter := domain.DiaryFilter{
+		UserID: userID,
+		Page:   page,
+	}
+
+	if filter.From, err = parseTimeQuery(r, "from"); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if filter.To, err = parseTimeQuery(r, "to"); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	entries, err := h.App.Service.Diary.List(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	helper.SetPageLinks(r, entries)
+
+	h.App.WriteJSON(w, http.StatusOK, entries, nil)
+}
+
 func (h *DiaryHandler) GetDiaryHandler(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middleware.UserIDKey).(int64)
 
@@ -70,10 +111,13 @@ func (h *DiaryHandler) CreateLogHandler(w http.ResponseWriter, r *http.Request)
 
 	// Gunakan string untuk tanggal di payload
 	var payload struct {
-		FoodID         int64   `json:"food_id"`
-		AmountConsumed float64 `json:"amount_consumed"`
-		ConsumedAt     string  `json:"consumed_at"` // Ubah jadi string
-		MealType       string  `json:"meal_type"`
+		FoodID         int64    `json:"food_id"`
+		AmountConsumed float64  `json:"amount_consumed"`
+		Unit           string   `json:"unit"`
+		PortionID      *int64   `json:"portion_id"`
+		Quantity       *float64 `json:"quantity"`
+		ConsumedAt     string   `json:"consumed_at"` // Ubah jadi string
+		MealType       string   `json:"meal_type"`
 	}
 
 	if err := h.App.ReadJSON(w, r, &payload); err != nil {
@@ -104,6 +148,9 @@ func (h *DiaryHandler) CreateLogHandler(w http.ResponseWriter, r *http.Request)
 		UserID:         userID,
 		FoodID:         payload.FoodID,
 		AmountConsumed: payload.AmountConsumed,
+		Unit:           payload.Unit,
+		PortionID:      payload.PortionID,
+		Quantity:       payload.Quantity,
 		ConsumedAt:     consumedAt, // Sekarang sudah bertipe time.Time
 		MealType:       payload.MealType,
 	}
@@ -116,6 +163,22 @@ func (h *DiaryHandler) CreateLogHandler(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		if errors.Is(err, domain.ErrEmailNotVerified) {
+			h.App.ErrorResponse(w, r, http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotFound) {
+			h.App.ErrorResponse(w, r, http.StatusUnprocessableEntity, "food not found")
+			return
+		}
+
+		if errors.Is(err, domain.ErrInvalidPortion) || errors.Is(err, domain.ErrInvalidUnit) ||
+			errors.Is(err, domain.ErrIncompatibleUnit) || errors.Is(err, domain.ErrDensityRequired) {
+			h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		h.App.ServerErrorResponse(w, r, err)
 		return
 	}
@@ -135,6 +198,9 @@ func (h *DiaryHandler) UpdateLogHandler(w http.ResponseWriter, r *http.Request)
 
 	var payload struct {
 		AmountConsumed *float64 `json:"amount_consumed"`
+		Unit           string   `json:"unit"`
+		PortionID      *int64   `json:"portion_id"`
+		Quantity       *float64 `json:"quantity"`
 		ConsumedAt     *string  `json:"consumed_at"`
 		MealType       *string  `json:"meal_type"`
 	}
@@ -176,6 +242,9 @@ func (h *DiaryHandler) UpdateLogHandler(w http.ResponseWriter, r *http.Request)
 	input := &domain.DiaryUpdateInput{
 		ID:             diaryID,
 		AmountConsumed: payload.AmountConsumed,
+		Unit:           payload.Unit,
+		PortionID:      payload.PortionID,
+		Quantity:       payload.Quantity,
 		ConsumedAt:     finalTime, // Ini akan nil jika tidak dikirim di JSON
 		MealType:       payload.MealType,
 	}
@@ -188,6 +257,22 @@ func (h *DiaryHandler) UpdateLogHandler(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		if errors.Is(err, domain.ErrEmailNotVerified) {
+			h.App.ErrorResponse(w, r, http.StatusForbidden, err.Error())
+			return
+		}
+
+		if errors.Is(err, domain.ErrNotFound) {
+			h.App.ErrorResponse(w, r, http.StatusUnprocessableEntity, "food not found")
+			return
+		}
+
+		if errors.Is(err, domain.ErrInvalidPortion) || errors.Is(err, domain.ErrInvalidUnit) ||
+			errors.Is(err, domain.ErrIncompatibleUnit) || errors.Is(err, domain.ErrDensityRequired) {
+			h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		h.App.ServerErrorResponse(w, r, err)
 		return
 	}
@@ -206,6 +291,10 @@ func (h *DiaryHandler) DeleteLogHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err = h.App.Service.Diary.Delete(r.Context(), userID, diaryID); err != nil {
+		if errors.Is(err, domain.ErrEmailNotVerified) {
+			h.App.ErrorResponse(w, r, http.StatusForbidden, err.Error())
+			return
+		}
 		h.App.ServerErrorResponse(w, r, err)
 		return
 	}
@@ -4,13 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/MyFirstGo/internal/app"
 	"github.com/MyFirstGo/internal/domain"
 	"github.com/MyFirstGo/internal/helper"
+	"github.com/MyFirstGo/internal/middleware"
 	"github.com/MyFirstGo/internal/store"
 	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
 )
 
 type FoodHandler struct {
@@ -26,23 +31,146 @@ func NewFoodHandler(app *app.Application) *FoodHandler {
 func (h *FoodHandler) GetFoodsHandler(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 
+	nutrients, err := parseNutrientRanges(q)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	page, err := helper.ReadPageRequest(r, 10)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
 	filter := domain.FoodFilter{
-		Query:       q.Get("q"),
-		MinCalories: helper.ReadFloatQuery(r, "min_cal", 0),
-		MaxCalories: helper.ReadFloatQuery(r, "max_cal", 0),
-		Limit:       helper.ReadIntQuery(r, "limit", 10),
-		Offset:      (helper.ReadIntQuery(r, "page", 1) - 1) * 10,
+		Query:      q.Get("q"),
+		Nutrients:  nutrients,
+		Sort:       parseFoodSort(q.Get("sort")),
+		Basis:      q.Get("basis"),
+		Page:       page,
+		Viewer:     foodViewer(r),
+		Visibility: q.Get("visibility"),
 	}
 
 	foods, err := h.App.Service.Foods.Search(r.Context(), filter)
 	if err != nil {
-		h.App.ServerErrorResponse(w, r, err)
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			h.App.ValidationErrorResponse(w, r, err)
+		case errors.Is(err, domain.ErrUnknownNutrient), errors.Is(err, domain.ErrInvalidCursor):
+			h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
 		return
 	}
 
+	helper.SetPageLinks(r, foods)
+
 	h.App.WriteJSON(w, http.StatusOK, foods, nil)
 }
 
+// foodViewer membaca user dari token (jika ada) untuk aturan visibility food. Token atau
+// API key tanpa foods:read diperlakukan seperti anonim.
+func foodViewer(r *http.Request) domain.FoodViewer {
+	claims, ok := r.Context().Value(middleware.ClaimsKey).(*domain.TokenClaims)
+	if !ok || !claims.Can(domain.PermFoodsRead) {
+		return domain.FoodViewer{}
+	}
+
+	return domain.FoodViewer{
+		UserID: claims.UserID,
+		Editor: claims.Can(domain.PermFoodsWrite),
+	}
+}
+
+// foodEditor dipakai endpoint yang mengubah food, route-nya sudah mewajibkan foods:create
+// sehingga UserID selalu diisi sebagai pemilik
+func foodEditor(r *http.Request) domain.FoodViewer {
+	claims, ok := r.Context().Value(middleware.ClaimsKey).(*domain.TokenClaims)
+	if !ok {
+		return domain.FoodViewer{}
+	}
+
+	return domain.FoodViewer{
+		UserID: claims.UserID,
+		Editor: claims.Can(domain.PermFoodsWrite),
+	}
+}
+
+// parseNutrientRanges membaca filter nutrient dari query <slug>_min dan <slug>_max,
+// misal protein_min=20&sodium_max=0.4. min_cal dan max_cal tetap didukung.
+func parseNutrientRanges(q url.Values) ([]domain.NutrientRange, error) {
+	ranges := map[string]*domain.NutrientRange{}
+	bound := func(slug string) *domain.NutrientRange {
+		if ranges[slug] == nil {
+			ranges[slug] = &domain.NutrientRange{Slug: slug}
+		}
+		return ranges[slug]
+	}
+
+	for key := range q {
+		var slug string
+		var isMin bool
+		switch {
+		case key == "min_cal", key == "max_cal":
+			slug, isMin = domain.NutrientCalories, key == "min_cal"
+		case strings.HasSuffix(key, "_min"):
+			slug, isMin = strings.TrimSuffix(key, "_min"), true
+		case strings.HasSuffix(key, "_max"):
+			slug = strings.TrimSuffix(key, "_max")
+		default:
+			continue
+		}
+
+		value, err := strconv.ParseFloat(q.Get(key), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s", key)
+		}
+
+		if isMin {
+			bound(slug).Min = &value
+		} else {
+			bound(slug).Max = &value
+		}
+	}
+
+	slugs := make([]string, 0, len(ranges))
+	for slug := range ranges {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	res := make([]domain.NutrientRange, 0, len(slugs))
+	for _, slug := range slugs {
+		res = append(res, *ranges[slug])
+	}
+
+	return res, nil
+}
+
+// parseFoodSort membaca sort=<field>, awalan "-" untuk descending dan akhiran
+// "_per_100kcal" untuk rasio, misal sort=-protein_per_100kcal
+func parseFoodSort(value string) domain.FoodSort {
+	var res domain.FoodSort
+
+	if strings.HasPrefix(value, "-") {
+		res.Desc = true
+		value = strings.TrimPrefix(value, "-")
+	}
+
+	if strings.HasSuffix(value, "_per_100kcal") {
+		res.Per100kcal = true
+		value = strings.TrimSuffix(value, "_per_100kcal")
+	}
+
+	res.Field = value
+
+	return res
+}
+
 func (h *FoodHandler) GetFoodByIdHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "foodID")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -51,7 +179,7 @@ func (h *FoodHandler) GetFoodByIdHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	food, err := h.App.Service.Foods.GetByID(r.Context(), id)
+	food, err := h.App.Service.Foods.GetByID(r.Context(), foodViewer(r), id)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			h.App.NotFoundResponse(w, r)
@@ -66,16 +194,21 @@ func (h *FoodHandler) GetFoodByIdHandler(w http.ResponseWriter, r *http.Request)
 
 func (h *FoodHandler) CreateFoodsHandler(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
-		Name        string  `json:"name"`
-		Description string  `json:"description"`
-		ServingSize float64 `json:"serving_size"`
-		ServingUnit string  `json:"serving_unit"`
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		ServingSize float64  `json:"serving_size"`
+		ServingUnit string   `json:"serving_unit"`
+		Density     *float64 `json:"density"`
 		Nutrients   []struct {
 			ID     int64   `json:"id"`
 			Name   string  `json:"name"`
 			Unit   string  `json:"unit"`
 			Amount float64 `json:"amount"`
 		} `json:"nutrients"`
+		Barcodes   []string             `json:"barcodes"`
+		Names      []domain.FoodName    `json:"names"`
+		Portions   []domain.FoodPortion `json:"portions"`
+		Visibility string               `json:"visibility"`
 	}
 
 	if err := h.App.ReadJSON(w, r, &payload); err != nil {
@@ -88,6 +221,11 @@ func (h *FoodHandler) CreateFoodsHandler(w http.ResponseWriter, r *http.Request)
 		Description: payload.Description,
 		ServingSize: &payload.ServingSize,
 		ServingUnit: &payload.ServingUnit,
+		Density:     payload.Density,
+		Barcodes:    payload.Barcodes,
+		Names:       payload.Names,
+		Portions:    payload.Portions,
+		Visibility:  payload.Visibility,
 	}
 
 	for _, n := range payload.Nutrients {
@@ -104,9 +242,9 @@ func (h *FoodHandler) CreateFoodsHandler(w http.ResponseWriter, r *http.Request)
 		})
 	}
 
-	food, err := h.App.Service.Foods.Create(r.Context(), input)
+	food, err := h.App.Service.Foods.Create(r.Context(), foodEditor(r), input)
 	if err != nil {
-		h.App.ServerErrorResponse(w, r, err)
+		h.foodInputErrorResponse(w, r, err)
 		return
 	}
 
@@ -126,10 +264,13 @@ func (h *FoodHandler) UpdateFoodsHandler(w http.ResponseWriter, r *http.Request)
 		Description *string  `json:"description"`
 		ServingSize *float64 `json:"serving_size"`
 		ServingUnit *string  `json:"serving_unit"`
+		Density     *float64 `json:"density"`
 		Nutrients   *[]struct {
 			ID     int64   `json:"id"`
 			Amount float64 `json:"amount"`
 		} `json:"nutrients"`
+		Barcodes *[]string          `json:"barcodes"`
+		Names    *[]domain.FoodName `json:"names"`
 	}
 
 	if err := h.App.ReadJSON(w, r, &payload); err != nil {
@@ -143,6 +284,9 @@ func (h *FoodHandler) UpdateFoodsHandler(w http.ResponseWriter, r *http.Request)
 		Description: payload.Description,
 		ServingSize: payload.ServingSize,
 		ServingUnit: payload.ServingUnit,
+		Density:     payload.Density,
+		Barcodes:    payload.Barcodes,
+		Names:       payload.Names,
 	}
 
 	if payload.Nutrients != nil {
@@ -157,10 +301,46 @@ func (h *FoodHandler) UpdateFoodsHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Panggil Service. Service yang bertanggung jawab ambil data lama & update.
-	food, err := h.App.Service.Foods.Update(r.Context(), id, input)
+	food, err := h.App.Service.Foods.Update(r.Context(), foodEditor(r), id, input)
 	if err != nil {
 		switch {
-		case errors.Is(err, domain.ErrNotFound):
+		case errors.Is(err, domain.ErrNotFound), errors.Is(err, store.ErrNotFound):
+			h.App.NotFoundResponse(w, r)
+		default:
+			h.foodInputErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, food, nil)
+}
+
+// foodInputErrorResponse menangani error validasi, barcode, nama dan hak akses dari create/update food
+func (h *FoodHandler) foodInputErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		h.App.ValidationErrorResponse(w, r, err)
+	case errors.Is(err, domain.ErrInvalidBarcode), errors.Is(err, domain.ErrDuplicateFoodName), errors.Is(err, domain.ErrPrivateBarcode),
+		errors.Is(err, domain.ErrRecipeComputed), errors.Is(err, domain.ErrInvalidPortion), errors.Is(err, domain.ErrDuplicatePortion),
+		errors.Is(err, domain.ErrInvalidUnit), errors.Is(err, domain.ErrDensityRequired):
+		h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrBarcodeTaken):
+		h.App.ErrorResponse(w, r, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrFoodForbidden):
+		h.App.ErrorResponse(w, r, http.StatusForbidden, err.Error())
+	default:
+		h.App.ServerErrorResponse(w, r, err)
+	}
+}
+
+func (h *FoodHandler) GetFoodByBarcodeHandler(w http.ResponseWriter, r *http.Request) {
+	food, err := h.App.Service.Foods.GetByBarcode(r.Context(), foodViewer(r), chi.URLParam(r, "code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidBarcode):
+			h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		case errors.Is(err, store.ErrNotFound):
 			h.App.NotFoundResponse(w, r)
 		default:
 			h.App.ServerErrorResponse(w, r, err)
@@ -180,10 +360,122 @@ func (h *FoodHandler) DeleteFoodsHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.App.Service.Foods.Delete(r.Context(), id); err != nil {
-		h.App.ServerErrorResponse(w, r, err)
+	if err := h.App.Service.Foods.Delete(r.Context(), foodEditor(r), id); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			h.App.NotFoundResponse(w, r)
+		case errors.Is(err, domain.ErrFoodForbidden):
+			h.App.ErrorResponse(w, r, http.StatusForbidden, err.Error())
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *FoodHandler) CreatePortionHandler(w http.ResponseWriter, r *http.Request) {
+	foodID, err := strconv.ParseInt(chi.URLParam(r, "foodID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, fmt.Errorf("invalid food ID format"))
+		return
+	}
+
+	var payload struct {
+		Name  string  `json:"name"`
+		Grams float64 `json:"grams"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	portion, err := h.App.Service.Foods.AddPortion(r.Context(), foodEditor(r), foodID, domain.FoodPortion{
+		Name:  payload.Name,
+		Grams: payload.Grams,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			h.App.NotFoundResponse(w, r)
+		default:
+			h.foodInputErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusCreated, portion, nil)
+}
+
+func (h *FoodHandler) DeletePortionHandler(w http.ResponseWriter, r *http.Request) {
+	foodID, err := strconv.ParseInt(chi.URLParam(r, "foodID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, fmt.Errorf("invalid food ID format"))
+		return
+	}
+
+	portionID, err := strconv.ParseInt(chi.URLParam(r, "portionID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, fmt.Errorf("invalid portion ID format"))
+		return
+	}
+
+	if err := h.App.Service.Foods.DeletePortion(r.Context(), foodEditor(r), foodID, portionID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			h.App.NotFoundResponse(w, r)
+		default:
+			h.foodInputErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SubmitFoodHandler mengajukan food private milik user ke katalog publik
+func (h *FoodHandler) SubmitFoodHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeVisibility(w, r, func(id int64) (*domain.Food, error) {
+		return h.App.Service.Foods.Submit(r.Context(), foodEditor(r), id)
+	})
+}
+
+func (h *FoodHandler) PublishFoodHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeVisibility(w, r, func(id int64) (*domain.Food, error) {
+		return h.App.Service.Foods.Publish(r.Context(), id)
+	})
+}
+
+func (h *FoodHandler) RejectFoodHandler(w http.ResponseWriter, r *http.Request) {
+	h.changeVisibility(w, r, func(id int64) (*domain.Food, error) {
+		return h.App.Service.Foods.Reject(r.Context(), id)
+	})
+}
+
+func (h *FoodHandler) changeVisibility(w http.ResponseWriter, r *http.Request, change func(int64) (*domain.Food, error)) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "foodID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, fmt.Errorf("invalid food ID format"))
+		return
+	}
+
+	food, err := change(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			h.App.NotFoundResponse(w, r)
+		case errors.Is(err, domain.ErrFoodForbidden):
+			h.App.ErrorResponse(w, r, http.StatusForbidden, err.Error())
+		case errors.Is(err, domain.ErrFoodNotPrivate), errors.Is(err, domain.ErrFoodNotSubmitted), errors.Is(err, domain.ErrConflict),
+			errors.Is(err, domain.ErrPrivateIngredient), errors.Is(err, domain.ErrInvalidIngredient):
+			h.App.ErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, food, nil)
+}
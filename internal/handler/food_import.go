@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/MyFirstGo/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+const maxFoodImportSize = 50 << 20
+
+// ImportFoodsHandler menerima multipart form: file (CSV atau NDJSON), format (opsional,
+// ditebak dari ekstensi file), dry_run, dan mapping berupa object JSON nama kolom ke field,
+// misal {"food": "name", "Caloric Value": "nutrients.calories"}
+func (h *FoodHandler) ImportFoodsHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxFoodImportSize)
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.App.ErrorResponse(w, r, http.StatusRequestEntityTooLarge, "file too large")
+			return
+		}
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+	defer file.Close()
+
+	input := &domain.FoodImportInput{
+		Format: r.FormValue("format"),
+		File:   file,
+		Size:   header.Size,
+	}
+
+	if input.Format == "" {
+		input.Format = foodImportFormat(header.Filename)
+	}
+
+	if v := r.FormValue("dry_run"); v != "" {
+		if input.DryRun, err = strconv.ParseBool(v); err != nil {
+			h.App.BadRequestResponse(w, r, errors.New("invalid dry_run"))
+			return
+		}
+	}
+
+	if v := r.FormValue("mapping"); v != "" {
+		if err := json.Unmarshal([]byte(v), &input.Mapping); err != nil {
+			h.App.BadRequestResponse(w, r, errors.New("mapping must be a JSON object of column names to fields"))
+			return
+		}
+	}
+
+	imp, err := h.App.Service.FoodImports.Request(r.Context(), input)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			h.App.ValidationErrorResponse(w, r, err)
+		case errors.Is(err, domain.ErrInvalidImport):
+			h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusAccepted, imp, nil)
+}
+
+func foodImportFormat(filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return domain.FoodImportCSV
+	case ".ndjson", ".jsonl":
+		return domain.FoodImportNDJSON
+	}
+	return ""
+}
+
+// GetFoodImportHandler mengembalikan status dan progress import
+func (h *FoodHandler) GetFoodImportHandler(w http.ResponseWriter, r *http.Request) {
+	importID, err := strconv.ParseInt(chi.URLParam(r, "importID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	imp, err := h.App.Service.FoodImports.Get(r.Context(), importID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.App.NotFoundResponse(w, r)
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, imp, nil)
+}
+
+// ListFoodImportErrorsHandler: ?page=1&limit=100
+func (h *FoodHandler) ListFoodImportErrorsHandler(w http.ResponseWriter, r *http.Request) {
+	importID, err := strconv.ParseInt(chi.URLParam(r, "importID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	limit := helper.ReadIntQuery(r, "limit", 100)
+	filter := domain.FoodImportErrorFilter{
+		ImportID: importID,
+		Limit:    limit,
+		Offset:   (helper.ReadIntQuery(r, "page", 1) - 1) * limit,
+	}
+
+	errs, err := h.App.Service.FoodImports.ListErrors(r.Context(), filter)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			h.App.ValidationErrorResponse(w, r, err)
+		case errors.Is(err, store.ErrNotFound):
+			h.App.NotFoundResponse(w, r)
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, errs, nil)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/store"
+	"github.com/go-playground/validator/v10"
+)
+
+// ListDuplicatesHandler: ?min_similarity=0.5&max_distance=0.25&limit=20
+func (h *FoodHandler) ListDuplicatesHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := domain.FoodDuplicateFilter{
+		MinSimilarity: 0.5,
+		MaxDistance:   0.25,
+		Limit:         20,
+	}
+
+	for key, dest := range map[string]*float64{
+		"min_similarity": &filter.MinSimilarity,
+		"max_distance":   &filter.MaxDistance,
+	} {
+		if v := q.Get(key); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				h.App.BadRequestResponse(w, r, fmt.Errorf("invalid %s", key))
+				return
+			}
+			*dest = f
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			h.App.BadRequestResponse(w, r, fmt.Errorf("invalid limit"))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	duplicates, err := h.App.Service.Foods.FindDuplicates(r.Context(), filter)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			h.App.ValidationErrorResponse(w, r, err)
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, duplicates, nil)
+}
+
+func (h *FoodHandler) MergeFoodsHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		CanonicalID  int64   `json:"canonical_id"`
+		DuplicateIDs []int64 `json:"duplicate_ids"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	res, err := h.App.Service.Foods.Merge(r.Context(), &domain.FoodMergeInput{
+		CanonicalID:  payload.CanonicalID,
+		DuplicateIDs: payload.DuplicateIDs,
+	})
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			h.App.ValidationErrorResponse(w, r, err)
+		case errors.Is(err, store.ErrNotFound):
+			h.App.NotFoundResponse(w, r)
+		case errors.Is(err, domain.ErrInvalidMerge), errors.Is(err, domain.ErrMergeIngredient):
+			h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, res, nil)
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/MyFirstGo/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *FoodHandler) ListRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	foodID, err := strconv.ParseInt(chi.URLParam(r, "foodID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, fmt.Errorf("invalid food ID format"))
+		return
+	}
+
+	revisions, err := h.App.Service.Foods.ListRevisions(r.Context(), foodViewer(r), foodID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.App.NotFoundResponse(w, r)
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, revisions, nil)
+}
+
+func (h *FoodHandler) GetRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	foodID, revision, ok := h.revisionParams(w, r)
+	if !ok {
+		return
+	}
+
+	rev, err := h.App.Service.Foods.GetRevision(r.Context(), foodViewer(r), foodID, revision)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.App.NotFoundResponse(w, r)
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, rev, nil)
+}
+
+// DiffRevisionsHandler membandingkan dua revisi: ?from=1&to=3
+func (h *FoodHandler) DiffRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	foodID, err := strconv.ParseInt(chi.URLParam(r, "foodID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, fmt.Errorf("invalid food ID format"))
+		return
+	}
+
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		h.App.BadRequestResponse(w, r, fmt.Errorf("invalid from revision"))
+		return
+	}
+
+	to, err := strconv.Atoi(r.URL.Query().Get("to"))
+	if err != nil {
+		h.App.BadRequestResponse(w, r, fmt.Errorf("invalid to revision"))
+		return
+	}
+
+	diff, err := h.App.Service.Foods.DiffRevisions(r.Context(), foodViewer(r), foodID, from, to)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.App.NotFoundResponse(w, r)
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, diff, nil)
+}
+
+func (h *FoodHandler) RollbackHandler(w http.ResponseWriter, r *http.Request) {
+	foodID, revision, ok := h.revisionParams(w, r)
+	if !ok {
+		return
+	}
+
+	food, err := h.App.Service.Foods.Rollback(r.Context(), foodEditor(r), foodID, revision)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.App.NotFoundResponse(w, r)
+			return
+		}
+		h.foodInputErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, food, nil)
+}
+
+func (h *FoodHandler) revisionParams(w http.ResponseWriter, r *http.Request) (int64, int, bool) {
+	foodID, err := strconv.ParseInt(chi.URLParam(r, "foodID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, fmt.Errorf("invalid food ID format"))
+		return 0, 0, false
+	}
+
+	revision, err := strconv.Atoi(chi.URLParam(r, "revision"))
+	if err != nil {
+		h.App.BadRequestResponse(w, r, fmt.Errorf("invalid revision format"))
+		return 0, 0, false
+	}
+
+	return foodID, revision, true
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// Cookie untuk mengikat state OIDC ke browser yang memulai login
+const oidcStateCookie = "oidc_state"
+
+func (h *AuthHandler) OIDCStartHandler(w http.ResponseWriter, r *http.Request) {
+	provider := chi.URLParam(r, "provider")
+
+	auth, err := h.App.Service.Auth.StartOIDC(r.Context(), provider)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownProvider) {
+			h.App.NotFoundResponse(w, r)
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    auth.State,
+		Path:     "/v1/auth/oidc/" + provider,
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, auth.URL, http.StatusFound)
+}
+
+func (h *AuthHandler) OIDCCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	provider := chi.URLParam(r, "provider")
+	query := r.URL.Query()
+
+	// User membatalkan login atau provider menolak request
+	if providerErr := query.Get("error"); providerErr != "" {
+		h.App.ErrorResponse(w, r, http.StatusUnauthorized, providerErr)
+		return
+	}
+
+	state := query.Get("state")
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		h.App.ErrorResponse(w, r, http.StatusUnauthorized, domain.ErrInvalidToken.Error())
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:   oidcStateCookie,
+		Path:   "/v1/auth/oidc/" + provider,
+		MaxAge: -1,
+	})
+
+	input := domain.OIDCCallbackInput{
+		Provider:  provider,
+		Code:      query.Get("code"),
+		State:     state,
+		UserAgent: r.UserAgent(),
+		IPAddress: helper.ClientIP(r),
+	}
+
+	res, err := h.App.Service.Auth.LoginOIDC(r.Context(), input)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			h.App.ValidationErrorResponse(w, r, err)
+		case errors.Is(err, domain.ErrUnknownProvider):
+			h.App.NotFoundResponse(w, r)
+		case errors.Is(err, domain.ErrInvalidToken):
+			h.App.ErrorResponse(w, r, http.StatusUnauthorized, domain.ErrInvalidToken.Error())
+		case errors.Is(err, domain.ErrEmailNotVerified):
+			h.App.ErrorResponse(w, r, http.StatusForbidden, err.Error())
+		case errors.Is(err, domain.ErrIdentityConflict), errors.Is(err, domain.ErrDuplicateEmail):
+			h.App.ErrorResponse(w, r, http.StatusConflict, err.Error())
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, res, nil)
+}
@@ -113,3 +113,47 @@ func (h *ProfileHandler) UpdatePasswordHandler(w http.ResponseWriter, r *http.Re
 
 	h.App.WriteJSON(w, http.StatusOK, user, nil)
 }
+
+// DeleteAccountHandler menjadwalkan penghapusan akun, bukan menghapus langsung
+func (h *ProfileHandler) DeleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	var payload struct {
+		Password string `json:"password"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	claims := r.Context().Value(middleware.ClaimsKey).(*domain.TokenClaims)
+
+	input := domain.DeleteAccountInput{
+		Password:  payload.Password,
+		SessionID: claims.SessionID,
+	}
+
+	user, err := h.App.Service.Users.ScheduleDeletion(r.Context(), userID, input)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			h.App.ValidationErrorResponse(w, r, err)
+		case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrReauthRequired):
+			h.App.ErrorResponse(w, r, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, domain.ErrDeletionScheduled):
+			h.App.ErrorResponse(w, r, http.StatusConflict, err.Error())
+		case errors.Is(err, store.ErrNotFound):
+			h.App.NotFoundResponse(w, r)
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusAccepted, map[string]any{
+		"message":               "akun akan dihapus permanen, login kembali sebelum jadwal untuk membatalkan",
+		"deletion_scheduled_at": user.DeletionScheduledAt,
+	}, nil)
+}
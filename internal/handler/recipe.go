@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/MyFirstGo/internal/app"
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+type RecipeHandler struct {
+	App *app.Application
+}
+
+func NewRecipeHandler(app *app.Application) *RecipeHandler {
+	return &RecipeHandler{App: app}
+}
+
+type recipePayload struct {
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	YieldServings *float64 `json:"yield_servings"`
+	YieldWeight   *float64 `json:"yield_weight"`
+	Ingredients   []struct {
+		FoodID   int64   `json:"food_id"`
+		Quantity float64 `json:"quantity"`
+		Unit     string  `json:"unit"`
+	} `json:"ingredients"`
+	Names      []domain.FoodName `json:"names"`
+	Visibility string            `json:"visibility"`
+}
+
+func (p recipePayload) toInput() *domain.RecipeInput {
+	input := &domain.RecipeInput{
+		Name:          p.Name,
+		Description:   p.Description,
+		YieldServings: p.YieldServings,
+		YieldWeight:   p.YieldWeight,
+		Names:         p.Names,
+		Visibility:    p.Visibility,
+	}
+
+	for _, ing := range p.Ingredients {
+		input.Ingredients = append(input.Ingredients, domain.RecipeIngredientInput{
+			FoodID:   ing.FoodID,
+			Quantity: ing.Quantity,
+			Unit:     ing.Unit,
+		})
+	}
+
+	return input
+}
+
+func (h *RecipeHandler) CreateRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	var payload recipePayload
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	recipe, err := h.App.Service.Recipes.Create(r.Context(), foodEditor(r), payload.toInput())
+	if err != nil {
+		h.recipeErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusCreated, recipe, nil)
+}
+
+func (h *RecipeHandler) GetRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "foodID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, fmt.Errorf("invalid food ID format"))
+		return
+	}
+
+	recipe, err := h.App.Service.Recipes.Get(r.Context(), foodViewer(r), id)
+	if err != nil {
+		h.recipeErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, recipe, nil)
+}
+
+func (h *RecipeHandler) UpdateRecipeHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "foodID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, fmt.Errorf("invalid food ID format"))
+		return
+	}
+
+	var payload recipePayload
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	recipe, err := h.App.Service.Recipes.Update(r.Context(), foodEditor(r), id, payload.toInput())
+	if err != nil {
+		h.recipeErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, recipe, nil)
+}
+
+func (h *RecipeHandler) recipeErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		h.App.ValidationErrorResponse(w, r, err)
+	case errors.Is(err, store.ErrNotFound):
+		h.App.NotFoundResponse(w, r)
+	case errors.Is(err, domain.ErrInvalidIngredient), errors.Is(err, domain.ErrPrivateIngredient), errors.Is(err, domain.ErrDuplicateFoodName),
+		errors.Is(err, domain.ErrInvalidUnit), errors.Is(err, domain.ErrIncompatibleUnit), errors.Is(err, domain.ErrDensityRequired):
+		h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrFoodForbidden):
+		h.App.ErrorResponse(w, r, http.StatusForbidden, err.Error())
+	default:
+		h.App.ServerErrorResponse(w, r, err)
+	}
+}
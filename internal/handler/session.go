@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/MyFirstGo/internal/app"
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/middleware"
+	"github.com/MyFirstGo/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+type SessionHandler struct {
+	App *app.Application
+}
+
+func NewSessionHandler(app *app.Application) *SessionHandler {
+	return &SessionHandler{App: app}
+}
+
+func (h *SessionHandler) GetSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	claims := r.Context().Value(middleware.ClaimsKey).(*domain.TokenClaims)
+
+	sessions, err := h.App.Service.Sessions.List(r.Context(), claims)
+	if err != nil {
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, sessions, nil)
+}
+
+func (h *SessionHandler) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	sessionID, err := strconv.ParseInt(chi.URLParam(r, "sessionID"), 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := h.App.Service.Sessions.Revoke(r.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.App.NotFoundResponse(w, r)
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeAllSessionsHandler adalah "sign out everywhere"
+func (h *SessionHandler) RevokeAllSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	if err := h.App.Service.Sessions.RevokeAll(r.Context(), userID); err != nil {
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/MyFirstGo/internal/app"
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/middleware"
+	"github.com/go-playground/validator/v10"
+)
+
+type TwoFactorHandler struct {
+	App *app.Application
+}
+
+func NewTwoFactorHandler(app *app.Application) *TwoFactorHandler {
+	return &TwoFactorHandler{App: app}
+}
+
+func (h *TwoFactorHandler) EnrollTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	enrollment, err := h.App.Service.TwoFactor.EnrollTOTP(r.Context(), userID)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, enrollment, nil)
+}
+
+func (h *TwoFactorHandler) ConfirmTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	var payload struct {
+		Code string `json:"code"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	codes, err := h.App.Service.TwoFactor.ConfirmTOTP(r.Context(), userID, domain.TOTPCodeInput{Code: payload.Code})
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, codes, nil)
+}
+
+func (h *TwoFactorHandler) DisableTOTPHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	var payload struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	input := domain.DisableTOTPInput{
+		Password: payload.Password,
+		Code:     payload.Code,
+	}
+
+	if err := h.App.Service.TwoFactor.DisableTOTP(r.Context(), userID, input); err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	var payload struct {
+		Code string `json:"code"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	codes, err := h.App.Service.TwoFactor.RegenerateRecoveryCodes(r.Context(), userID, domain.TOTPCodeInput{Code: payload.Code})
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, codes, nil)
+}
+
+func (h *TwoFactorHandler) errorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		h.App.ValidationErrorResponse(w, r, err)
+	case errors.Is(err, domain.ErrInvalidMFACode), errors.Is(err, domain.ErrInvalidCredentials):
+		h.App.ErrorResponse(w, r, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, domain.ErrTOTPEnabled):
+		h.App.ErrorResponse(w, r, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrTOTPNotEnabled):
+		h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+	default:
+		h.App.ServerErrorResponse(w, r, err)
+	}
+}
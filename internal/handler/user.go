@@ -9,6 +9,7 @@ import (
 
 	"github.com/MyFirstGo/internal/app"
 	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
 	"github.com/MyFirstGo/internal/middleware"
 	"github.com/MyFirstGo/internal/store"
 	"github.com/go-chi/chi/v5"
@@ -24,25 +25,24 @@ func NewUserHandler(app *app.Application) *UserHandler {
 }
 
 func (h *UserHandler) GetUsersHandler(w http.ResponseWriter, r *http.Request) {
-	pageStr := r.URL.Query().Get("page")
-	sizeStr := r.URL.Query().Get("size")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil {
-		page = 1
-	}
-	size, err := strconv.Atoi(sizeStr)
+	page, err := helper.ReadPageRequest(r, 10)
 	if err != nil {
-		size = 10
+		h.App.BadRequestResponse(w, r, err)
+		return
 	}
-	ctx := r.Context()
 
-	users, err := h.App.Service.Users.GetPaginated(ctx, page, size)
+	users, err := h.App.Service.Users.GetPaginated(r.Context(), page)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			h.App.ErrorResponse(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
 		return
 	}
 
+	helper.SetPageLinks(r, users)
+
 	h.App.WriteJSON(w, http.StatusOK, users, nil)
 }
 
@@ -195,6 +195,64 @@ func (h *UserHandler) UpdateUserHandler(w http.ResponseWriter, r *http.Request)
 	h.App.WriteJSON(w, http.StatusOK, user, nil)
 }
 
+func (h *UserHandler) UpdateUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "userID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	var payload struct {
+		Role string `json:"role"`
+	}
+
+	if err := h.App.ReadJSON(w, r, &payload); err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	input := domain.UserRoleInput{
+		Role: domain.Role(payload.Role),
+	}
+
+	user, err := h.App.Service.Users.UpdateRole(r.Context(), id, input)
+	if err != nil {
+		var validationErrors validator.ValidationErrors
+		switch {
+		case errors.As(err, &validationErrors):
+			h.App.ValidationErrorResponse(w, r, err)
+		case errors.Is(err, store.ErrNotFound):
+			h.App.NotFoundResponse(w, r)
+		default:
+			h.App.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.App.WriteJSON(w, http.StatusOK, user, nil)
+}
+
+func (h *UserHandler) UnlockUserHandler(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "userID")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.App.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := h.App.Service.Auth.UnlockAccount(r.Context(), id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			h.App.NotFoundResponse(w, r)
+			return
+		}
+		h.App.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *UserHandler) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "userID")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -0,0 +1,69 @@
+package helper
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+)
+
+// Key untuk enkripsi data sensitif di database (secret TOTP, private key JWT, dll),
+// diisi SetEncryptionKey saat startup
+var encryptionKey *[sha256.Size]byte
+
+var ErrEncryptionKeyMissing = errors.New("DATA_ENCRYPTION_KEY is not set")
+
+// SetEncryptionKey menolak key kosong supaya data tidak dienkripsi dengan key yang bisa ditebak
+func SetEncryptionKey(key string) error {
+	if key == "" {
+		return ErrEncryptionKeyMissing
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	encryptionKey = &sum
+	return nil
+}
+
+// Encrypt mengenkripsi data dengan AES-256-GCM, nonce disimpan di depan ciphertext
+func Encrypt(plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func Decrypt(ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, data := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+
+	return gcm.Open(nil, nonce, data, nil)
+}
+
+func newGCM() (cipher.AEAD, error) {
+	if encryptionKey == nil {
+		return nil, ErrEncryptionKeyMissing
+	}
+
+	block, err := aes.NewCipher(encryptionKey[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -15,6 +15,15 @@ func IsDuplicateKeyError(err error) bool {
 	return false
 }
 
+// IsUniqueViolation seperti IsDuplicateKeyError tetapi hanya untuk constraint tertentu
+func IsUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505" && pqErr.Constraint == constraint
+	}
+	return false
+}
+
 func IsForeignKeyError(err error) bool {
 	// PostgreSQL
 	var pqErr *pq.Error
@@ -5,43 +5,147 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/MyFirstGo/internal/env"
+	"github.com/MyFirstGo/internal/domain"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte(env.GetString("JWT_SECRET", "secret"))
+// Jenis token, supaya challenge token 2FA tidak bisa dipakai sebagai access token
+const (
+	tokenTypeAccess    = "access"
+	tokenTypeChallenge = "mfa_challenge"
+)
+
+func GenerateToken(claims domain.TokenClaims) (string, error) {
+	mapClaims := jwt.MapClaims{
+		"typ":     tokenTypeAccess,
+		"user_id": claims.UserID,
+		"sid":     claims.SessionID,
+		"role":    string(claims.Role),
+		"jti":     claims.TokenID,
+		"exp":     claims.ExpiresAt.Unix(),
+		"iat":     time.Now().Unix(),
+	}
+
+	return signToken(mapClaims)
+}
 
-func GenerateToken(userID int64) (string, error) {
-	claims := jwt.MapClaims{
+func ValidateToken(tokenStr string) (*domain.TokenClaims, error) {
+	claims, err := parseToken(tokenStr, tokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, ok := claims["sid"].(float64)
+	if !ok {
+		return nil, errors.New("sid not found in claims")
+	}
+
+	role, ok := claims["role"].(string)
+	if !ok {
+		return nil, errors.New("role not found in claims")
+	}
+
+	res, err := baseClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	res.SessionID = int64(sessionID)
+	res.Role = domain.Role(role)
+
+	return res, nil
+}
+
+// GenerateChallengeToken membuat token berumur pendek untuk langkah kedua login (2FA)
+func GenerateChallengeToken(userID int64, jti string, expiresAt time.Time) (string, error) {
+	mapClaims := jwt.MapClaims{
+		"typ":     tokenTypeChallenge,
 		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+		"jti":     jti,
+		"exp":     expiresAt.Unix(),
 		"iat":     time.Now().Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return signToken(mapClaims)
+}
+
+func ValidateChallengeToken(tokenStr string) (*domain.TokenClaims, error) {
+	claims, err := parseToken(tokenStr, tokenTypeChallenge)
+	if err != nil {
+		return nil, err
+	}
+
+	return baseClaims(claims)
+}
+
+// signToken menandatangani claims dengan key yang sedang aktif, kid dicantumkan
+// di header supaya verifier (termasuk service lain lewat JWKS) tahu key mana yang dipakai
+func signToken(claims jwt.MapClaims) (string, error) {
+	key, err := signingKeyAt(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+
+	return token.SignedString(key.private)
 }
 
-func ValidateToken(tokenStr string) (int64, error) {
+func parseToken(tokenStr, typ string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, errors.New("kid not found in token header")
+		}
+
+		key, err := verificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		// Algoritma harus sesuai key, jangan percaya header alg begitu saja
+		if token.Method.Alg() != key.method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return jwtSecret, nil
-	})
+		return key.public, nil
+	}, jwt.WithValidMethods([]string{domain.SigningAlgEdDSA, domain.SigningAlgRS256}))
 
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		val, ok := claims["user_id"]
-		if !ok {
-			return 0, errors.New("user_id not found in claims")
-		}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if claims["typ"] != typ {
+		return nil, errors.New("unexpected token type")
+	}
+
+	return claims, nil
+}
+
+func baseClaims(claims jwt.MapClaims) (*domain.TokenClaims, error) {
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return nil, errors.New("user_id not found in claims")
+	}
+
+	jti, ok := claims["jti"].(string)
+	if !ok {
+		return nil, errors.New("jti not found in claims")
+	}
 
-		return int64(val.(float64)), nil
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, errors.New("exp not found in claims")
 	}
 
-	return 0, errors.New("invalid token")
+	return &domain.TokenClaims{
+		UserID:    int64(userID),
+		TokenID:   jti,
+		ExpiresAt: exp.Time,
+	}, nil
 }
@@ -0,0 +1,61 @@
+package helper
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BaseLocale mengambil bahasa dasar dari tag BCP 47, misal "id-ID" jadi "id"
+func BaseLocale(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+
+	return tag
+}
+
+// AcceptLanguages mengurai header Accept-Language menjadi daftar bahasa dasar
+// urut dari q tertinggi. Wildcard dan bahasa dengan q=0 dibuang.
+func AcceptLanguages(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var langs []weighted
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		locale := BaseLocale(tag)
+		if locale == "" || locale == "*" || q <= 0 || seen[locale] {
+			continue
+		}
+
+		seen[locale] = true
+		langs = append(langs, weighted{locale, q})
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	locales := make([]string, 0, len(langs))
+	for _, l := range langs {
+		locales = append(locales, l.locale)
+	}
+
+	return locales
+}
@@ -0,0 +1,68 @@
+package helper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+// EncodeCursor menyandikan cursor menjadi string opaque yang aman untuk query string
+func EncodeCursor(cursor *domain.Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(value string) (*domain.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	cursor := &domain.Cursor{}
+	if err := json.Unmarshal(raw, cursor); err != nil || len(cursor.Keys) == 0 {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// ReadPageRequest membaca ?cursor=, ?limit= dan ?total=true
+func ReadPageRequest(r *http.Request, defaultLimit int) (domain.PageRequest, error) {
+	q := r.URL.Query()
+
+	page := domain.PageRequest{
+		Limit:     ReadIntQuery(r, "limit", defaultLimit),
+		WithTotal: q.Get("total") == "true",
+	}
+
+	if value := q.Get("cursor"); value != "" {
+		cursor, err := DecodeCursor(value)
+		if err != nil {
+			return page, err
+		}
+		page.Cursor = cursor
+	}
+
+	return page, nil
+}
+
+// SetPageLinks mengisi link next/prev dari cursor halaman, query string lain tetap dipertahankan
+func SetPageLinks[T any](r *http.Request, page *domain.Page[T]) {
+	page.Next = pageLink(r, page.NextCursor)
+	page.Prev = pageLink(r, page.PrevCursor)
+}
+
+func pageLink(r *http.Request, cursor *domain.Cursor) *string {
+	if cursor == nil {
+		return nil
+	}
+
+	q := r.URL.Query()
+	q.Set("cursor", EncodeCursor(cursor))
+	q.Del("page")
+
+	link := r.URL.Path + "?" + q.Encode()
+	return &link
+}
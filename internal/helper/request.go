@@ -0,0 +1,17 @@
+package helper
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP mengambil IP client dari RemoteAddr tanpa port. Di belakang proxy terpercaya
+// RemoteAddr sudah diganti middleware RealIP.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
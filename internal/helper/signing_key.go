@@ -0,0 +1,201 @@
+package helper
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+type signingKey struct {
+	id          string
+	method      jwt.SigningMethod
+	private     crypto.Signer
+	public      crypto.PublicKey
+	activatesAt time.Time
+}
+
+type keyring struct {
+	keys map[string]*signingKey
+}
+
+// Keyring aktif, diganti utuh setiap kali LoadSigningKeys dipanggil
+var currentKeys atomic.Pointer[keyring]
+
+// GenerateSigningKey membuat pasangan key baru. Private key dikembalikan dalam keadaan terenkripsi.
+func GenerateSigningKey(alg string) (*domain.SigningKey, error) {
+	var private crypto.Signer
+
+	switch alg {
+	case domain.SigningAlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case domain.SigningAlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", alg)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := Encrypt(privateDER)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SigningKey{
+		ID:         uuid.NewString(),
+		Algorithm:  alg,
+		PrivateKey: encrypted,
+		PublicKey:  publicDER,
+	}, nil
+}
+
+// LoadSigningKeys mengganti key yang dipakai untuk menandatangani dan memverifikasi JWT
+func LoadSigningKeys(keys []*domain.SigningKey) error {
+	ring := &keyring{keys: make(map[string]*signingKey, len(keys))}
+
+	for _, k := range keys {
+		parsed, err := parseSigningKey(k)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", k.ID, err)
+		}
+		ring.keys[k.ID] = parsed
+	}
+
+	currentKeys.Store(ring)
+	return nil
+}
+
+// PublicJWKS mengembalikan semua public key yang masih berlaku untuk verifikasi
+func PublicJWKS() domain.JWKS {
+	jwks := domain.JWKS{Keys: []domain.JWK{}}
+
+	ring := currentKeys.Load()
+	if ring == nil {
+		return jwks
+	}
+
+	for _, k := range ring.keys {
+		jwk := domain.JWK{Kid: k.id, Use: "sig", Alg: k.method.Alg()}
+
+		switch pub := k.public.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+// signingKeyAt memilih key terbaru yang sudah aktif
+func signingKeyAt(now time.Time) (*signingKey, error) {
+	ring := currentKeys.Load()
+	if ring == nil {
+		return nil, errors.New("signing keys have not been loaded")
+	}
+
+	var current *signingKey
+	for _, k := range ring.keys {
+		if k.activatesAt.After(now) {
+			continue
+		}
+		if current == nil || k.activatesAt.After(current.activatesAt) {
+			current = k
+		}
+	}
+
+	if current == nil {
+		return nil, errors.New("no active signing key")
+	}
+
+	return current, nil
+}
+
+func verificationKey(kid string) (*signingKey, error) {
+	ring := currentKeys.Load()
+	if ring == nil {
+		return nil, errors.New("signing keys have not been loaded")
+	}
+
+	key, ok := ring.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+
+	return key, nil
+}
+
+func parseSigningKey(k *domain.SigningKey) (*signingKey, error) {
+	privateDER, err := Decrypt(k.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	private, err := x509.ParsePKCS8PrivateKey(privateDER)
+	if err != nil {
+		return nil, err
+	}
+
+	public, err := x509.ParsePKIXPublicKey(k.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var method jwt.SigningMethod
+	switch k.Algorithm {
+	case domain.SigningAlgEdDSA:
+		method = jwt.SigningMethodEdDSA
+	case domain.SigningAlgRS256:
+		method = jwt.SigningMethodRS256
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", k.Algorithm)
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key cannot sign")
+	}
+
+	return &signingKey{
+		id:          k.ID,
+		method:      method,
+		private:     signer,
+		public:      public,
+		activatesAt: k.ActivatesAt,
+	}, nil
+}
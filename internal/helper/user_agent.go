@@ -0,0 +1,51 @@
+package helper
+
+import "strings"
+
+// DeviceName membuat label singkat dari user agent, misal "Chrome on Windows".
+// Hanya untuk ditampilkan ke user, tidak dipakai untuk keputusan keamanan.
+func DeviceName(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := matchFirst(userAgent, [][2]string{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"okhttp", "Android app"},
+		{"CFNetwork", "iOS app"},
+		{"curl/", "curl"},
+	})
+
+	os := matchFirst(userAgent, [][2]string{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	})
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return "Unknown device"
+	}
+}
+
+func matchFirst(s string, candidates [][2]string) string {
+	for _, c := range candidates {
+		if strings.Contains(s, c[0]) {
+			return c[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+// FileMailer menulis email ke writer (file atau stdout) alih-alih mengirimnya.
+// Dipakai untuk development lokal dan test.
+type FileMailer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewFileMailer(w io.Writer) *FileMailer {
+	return &FileMailer{w: w}
+}
+
+func (m *FileMailer) Send(_ context.Context, email domain.Email) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := fmt.Fprintf(m.w, "=== %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), email.To, email.Subject, email.Body)
+	return err
+}
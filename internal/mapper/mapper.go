@@ -4,9 +4,12 @@ import "github.com/MyFirstGo/internal/domain"
 
 func UserToUserResponse(user *domain.User) *domain.UserResponse {
 	res := &domain.UserResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		TwoFactor:     user.TOTPEnabledAt != nil,
 	}
 
 	if user.Weight != nil {
@@ -37,6 +40,8 @@ func CreateDiaryInputToFoodDiary(input *domain.DiaryCreateInput) *domain.FoodDia
 		UserID:         input.UserID,
 		FoodID:         input.FoodID,
 		AmountConsumed: input.AmountConsumed,
+		PortionID:      input.PortionID,
+		Quantity:       input.Quantity,
 		ConsumedAt:     input.ConsumedAt,
 		MealType:       input.MealType,
 	}
@@ -59,6 +64,7 @@ func CreateFoodInputToFood(input *domain.CreateFoodInput) *domain.Food {
 		Description: input.Description,
 		ServingSize: input.ServingSize,
 		ServingUnit: input.ServingUnit,
+		Density:     input.Density,
 	}
 
 	return food
@@ -2,42 +2,99 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
-	"github.com/MyFirstGo/internal/helper"
+	"github.com/MyFirstGo/internal/app"
+	"github.com/MyFirstGo/internal/domain"
 )
 
 // Gunakan custom type untuk context key agar tidak bentrok dengan library lain
 type contextKey string
 
-const UserIDKey contextKey = "userID"
+const (
+	UserIDKey contextKey = "userID"
+	ClaimsKey contextKey = "claims"
+)
+
+func AuthMiddleware(app *app.Application) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var claims *domain.TokenClaims
+			var err error
+
+			// Integrasi (script, timbangan pintar) memakai API key, bukan JWT
+			if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+				claims, err = app.Service.APIKeys.AuthenticateAPIKey(r.Context(), apiKey)
+			} else {
+				authHeader := r.Header.Get("Authorization")
+				if authHeader == "" {
+					http.Error(w, "Authorization header is required", http.StatusUnauthorized)
+					return
+				}
+
+				parts := strings.Split(authHeader, " ")
+				if len(parts) != 2 || parts[0] != "Bearer" {
+					http.Error(w, "Invalid authorization format", http.StatusUnauthorized)
+					return
+				}
+
+				claims, err = app.Service.Auth.Authenticate(r.Context(), parts[1])
+			}
+
+			if err != nil {
+				if errors.Is(err, domain.ErrInvalidToken) {
+					http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+					return
+				}
+				app.ServerErrorResponse(w, r, err)
+				return
+			}
+
+			// Simpan userID dan claims ke context
+			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+			ctx = context.WithValue(ctx, ClaimsKey, claims)
+			ctx = domain.WithActor(ctx, domain.Actor{UserID: claims.UserID, APIKeyID: claims.APIKeyID})
+
+			// Lanjut ke handler berikutnya dengan context baru
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-func AuthMiddleware(next http.Handler) http.Handler {
+// OptionalAuth mengautentikasi request hanya jika membawa Authorization atau X-API-Key,
+// dipakai untuk endpoint publik yang hasilnya bergantung pada user (misal food private)
+func OptionalAuth(app *app.Application) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		auth := AuthMiddleware(app)(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" && r.Header.Get("X-API-Key") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			auth.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession menolak request yang memakai API key, dipakai untuk
+// pengaturan akun yang hanya boleh lewat login interaktif
+func RequireSession(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
+		claims, ok := r.Context().Value(ClaimsKey).(*domain.TokenClaims)
+		if !ok {
 			http.Error(w, "Authorization header is required", http.StatusUnauthorized)
 			return
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Invalid authorization format", http.StatusUnauthorized)
+		if claims.IsAPIKey() {
+			http.Error(w, "This resource is not available to API keys", http.StatusForbidden)
 			return
 		}
 
-		tokenString := parts[1]
-		userID, err := helper.ValidateToken(tokenString)
-		if err != nil {
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-			return
-		}
-
-		// Simpan userID ke context
-		ctx := context.WithValue(r.Context(), UserIDKey, userID)
-
-		// Lanjut ke handler berikutnya dengan context baru
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+)
+
+// Locale menyimpan bahasa dari header Accept-Language ke context untuk lokalisasi respons
+func Locale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := domain.WithLocales(r.Context(), helper.AcceptLanguages(r.Header.Get("Accept-Language")))
+
+		// Respons berbeda per bahasa, cache perlu tahu
+		w.Header().Add("Vary", "Accept-Language")
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+// RequirePermission harus dipasang setelah AuthMiddleware.
+// Untuk API key, permission juga harus termasuk scope key tersebut.
+func RequirePermission(perm domain.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(ClaimsKey).(*domain.TokenClaims)
+			if !ok {
+				http.Error(w, "Authorization header is required", http.StatusUnauthorized)
+				return
+			}
+
+			if !claims.Can(perm) {
+				http.Error(w, "You do not have permission to access this resource", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// OptionalPermission dipasang setelah OptionalAuth: request anonim tetap lolos,
+// tetapi token atau API key yang terautentikasi harus punya permission tersebut
+func OptionalPermission(perm domain.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(ClaimsKey).(*domain.TokenClaims)
+			if ok && !claims.Can(perm) {
+				http.Error(w, "You do not have permission to access this resource", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
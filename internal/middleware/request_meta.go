@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestMeta menyimpan IP, user agent dan request ID ke context untuk audit log.
+// Harus dipasang setelah middleware.RequestID dari chi.
+func RequestMeta(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := domain.WithRequestMeta(r.Context(), domain.RequestMeta{
+			IPAddress: helper.ClientIP(r),
+			UserAgent: r.UserAgent(),
+			RequestID: middleware.GetReqID(r.Context()),
+		})
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
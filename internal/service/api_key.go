@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/MyFirstGo/internal/store"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	apiKeyPrefix        = "ntk_"
+	apiKeyDisplayLength = 12
+)
+
+type APIKeyService struct {
+	store     store.Storage
+	validator validator.Validate
+}
+
+// Create membuat API key baru. Key asli hanya dikembalikan di sini, yang disimpan hanya hash-nya.
+func (s *APIKeyService) Create(ctx context.Context, userID int64, payload domain.CreateAPIKeyInput) (*domain.CreatedAPIKey, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	if payload.ExpiresAt != nil && !payload.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrInvalidExpiry
+	}
+
+	token, _, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	rawKey := apiKeyPrefix + token
+
+	key := domain.APIKey{
+		UserID:    userID,
+		Name:      payload.Name,
+		Prefix:    rawKey[:apiKeyDisplayLength],
+		KeyHash:   helper.HashToken(rawKey),
+		Scopes:    uniqueScopes(payload.Scopes),
+		ExpiresAt: payload.ExpiresAt,
+	}
+
+	if err := s.store.APIKeys.Create(ctx, &key); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditAPIKeyCreate, domain.AuditTargetAPIKey, key.ID, map[string]domain.AuditChange{
+		"scopes": {From: nil, To: key.Scopes},
+	})
+
+	return &domain.CreatedAPIKey{APIKey: key, Key: rawKey}, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, userID int64) ([]*domain.APIKey, error) {
+	return s.store.APIKeys.ListByUser(ctx, userID)
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, userID, keyID int64) error {
+	if err := s.store.APIKeys.Revoke(ctx, userID, keyID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditAPIKeyRevoke, domain.AuditTargetAPIKey, keyID, nil)
+
+	return nil
+}
+
+// AuthenticateAPIKey memvalidasi key dari header X-API-Key menjadi claims
+// yang aksesnya dibatasi scope key tersebut.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, rawKey string) (*domain.TokenClaims, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	key, role, err := s.store.APIKeys.GetActiveByHash(ctx, helper.HashToken(rawKey))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if err := s.store.APIKeys.TouchLastUsed(ctx, key.ID); err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenClaims{
+		UserID:   key.UserID,
+		Role:     role,
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+func uniqueScopes(scopes []domain.Permission) []domain.Permission {
+	seen := make(map[domain.Permission]bool, len(scopes))
+	out := make([]domain.Permission, 0, len(scopes))
+	for _, scope := range scopes {
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		out = append(out, scope)
+	}
+	return out
+}
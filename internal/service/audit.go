@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/store"
+	"github.com/go-playground/validator/v10"
+)
+
+// Field yang selalu berubah dan tidak berguna di diff audit
+var auditIgnoredFields = map[string]bool{"updated_at": true}
+
+type AuditService struct {
+	store     store.Storage
+	validator validator.Validate
+}
+
+func (s *AuditService) List(ctx context.Context, filter domain.AuditFilter) (*domain.Page[*domain.AuditEvent], error) {
+	filter.Page = normalizePage(filter.Page)
+
+	return s.store.Audit.List(ctx, filter)
+}
+
+// recordAudit mencatat event ke audit log. Actor dan metadata request diambil dari
+// context yang diisi middleware. Kegagalan hanya di-log supaya perubahan yang sudah
+// tersimpan tidak dilaporkan gagal ke client.
+func recordAudit(ctx context.Context, st store.Storage, action, targetType string, targetID int64, changes map[string]domain.AuditChange) {
+	event := &domain.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   &targetID,
+		Changes:    changes,
+	}
+
+	if actor, ok := domain.ActorFrom(ctx); ok {
+		event.ActorID = &actor.UserID
+		if actor.APIKeyID != 0 {
+			event.ActorAPIKey = &actor.APIKeyID
+		}
+	}
+
+	meta := domain.RequestMetaFrom(ctx)
+	if meta.IPAddress != "" {
+		event.IPAddress = &meta.IPAddress
+	}
+	if meta.UserAgent != "" {
+		event.UserAgent = &meta.UserAgent
+	}
+	if meta.RequestID != "" {
+		event.RequestID = &meta.RequestID
+	}
+
+	if err := st.Audit.Create(context.WithoutCancel(ctx), event); err != nil {
+		log.Printf("Failed to record audit event %s on %s %d: %v", action, targetType, targetID, err)
+	}
+}
+
+// auditDiff membandingkan dua nilai lewat representasi JSON-nya, jadi field
+// dengan tag json:"-" (misal password) tidak pernah masuk ke audit log
+func auditDiff(before, after any) map[string]domain.AuditChange {
+	from, err := toJSONMap(before)
+	if err != nil {
+		return nil
+	}
+
+	to, err := toJSONMap(after)
+	if err != nil {
+		return nil
+	}
+
+	changes := map[string]domain.AuditChange{}
+	for key, value := range to {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if !reflect.DeepEqual(from[key], value) {
+			changes[key] = domain.AuditChange{From: from[key], To: value}
+		}
+	}
+
+	for key, value := range from {
+		if _, ok := to[key]; !ok && !auditIgnoredFields[key] {
+			changes[key] = domain.AuditChange{From: value, To: nil}
+		}
+	}
+
+	return changes
+}
+
+func toJSONMap(v any) (map[string]any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]any{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
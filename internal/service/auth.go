@@ -2,18 +2,22 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
 
 	"github.com/MyFirstGo/internal/domain"
 	"github.com/MyFirstGo/internal/helper"
 	"github.com/MyFirstGo/internal/store"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
 	store     store.Storage
 	validator validator.Validate
+	config    Config
 }
 
 func (s *AuthService) Login(ctx context.Context, payload domain.UserLoginInput) (*domain.LoginResponse, error) {
@@ -21,24 +25,410 @@ func (s *AuthService) Login(ctx context.Context, payload domain.UserLoginInput)
 		return nil, err
 	}
 
+	if err := s.checkLockout(ctx, payload.Email, payload.IPAddress); err != nil {
+		return nil, err
+	}
+
 	user, err := s.store.Users.GetByEmail(ctx, payload.Email)
 	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			if err := s.recordLoginFailure(ctx, payload.Email, payload.IPAddress); err != nil {
+				return nil, err
+			}
+		}
 		return nil, err
 	}
 
 	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.Password)); err != nil {
 		log.Printf("Failed login attempt for user ID: %d", user.ID)
+		if err := s.recordLoginFailure(ctx, payload.Email, payload.IPAddress); err != nil {
+			return nil, err
+		}
 		return nil, domain.ErrInvalidCredentials
 	}
 
-	token, err := helper.GenerateToken(user.ID)
+	if s.config.EmailVerification == VerifyLogin && user.EmailVerifiedAt == nil {
+		return nil, domain.ErrEmailNotVerified
+	}
+
+	// Password benar tapi user memakai 2FA, lanjut ke langkah kedua
+	if user.TOTPEnabledAt != nil {
+		return s.issueChallenge(user)
+	}
+
+	return s.completeLogin(ctx, user, payload.UserAgent, payload.IPAddress)
+}
+
+// LoginMFA adalah langkah kedua login: menukar challenge token dan kode TOTP
+// (atau recovery code) dengan access token.
+func (s *AuthService) LoginMFA(ctx context.Context, payload domain.MFALoginInput) (*domain.LoginResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	claims, err := helper.ValidateChallengeToken(payload.ChallengeToken)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	// Challenge token hanya boleh dipakai sekali, dicabut sebelum kode diperiksa supaya dua
+	// request bersamaan dengan token yang sama tidak sama-sama lolos. Kode yang salah berarti
+	// user harus login ulang dengan password.
+	first, err := s.store.Sessions.ConsumeToken(ctx, claims.TokenID, claims.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if !first {
+		return nil, domain.ErrInvalidToken
+	}
+
+	user, err := s.store.Users.GetByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if err := s.checkLockout(ctx, user.Email, payload.IPAddress); err != nil {
+		return nil, err
+	}
+
+	state, err := s.store.TwoFactor.GetTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.EnabledAt == nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if payload.Code != "" {
+		err = verifyTOTP(ctx, s.store, user.ID, state, payload.Code)
+	} else {
+		err = useRecoveryCode(ctx, s.store, user.ID, payload.RecoveryCode)
+	}
+
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidMFACode) {
+			log.Printf("Failed 2FA attempt for user ID: %d", user.ID)
+			if err := s.recordLoginFailure(ctx, user.Email, payload.IPAddress); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, user, payload.UserAgent, payload.IPAddress)
+}
+
+func (s *AuthService) issueChallenge(user *domain.User) (*domain.LoginResponse, error) {
+	challenge, err := helper.GenerateChallengeToken(user.ID, uuid.NewString(), time.Now().Add(s.config.MFAChallengeTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.LoginResponse{
+		Type:           "mfa",
+		ExpiresIn:      int64(s.config.MFAChallengeTTL.Seconds()),
+		MFARequired:    true,
+		ChallengeToken: challenge,
+	}, nil
+}
+
+// completeLogin membuat session baru setelah semua faktor login terverifikasi
+func (s *AuthService) completeLogin(ctx context.Context, user *domain.User, userAgent, ip string) (*domain.LoginResponse, error) {
+	// Counter IP sengaja tidak di-reset supaya login ke akun sendiri tidak membuka blokir IP
+	if err := s.store.LoginAttempts.Reset(ctx, accountAttemptKey(user.Email)); err != nil {
+		return nil, err
+	}
+
+	if user.DeletionScheduledAt != nil {
+		if err := s.cancelDeletion(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	session := &domain.Session{
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.config.RefreshTokenTTL),
+	}
+
+	if userAgent != "" {
+		session.UserAgent = &userAgent
+	}
+
+	if ip != "" {
+		session.IPAddress = &ip
+	}
+
+	if err := s.store.Sessions.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	res, err := s.issueTokens(ctx, session, user.Role)
 	if err != nil {
 		log.Printf("Failed to generate token for user %d: %v", user.ID, err)
 		return nil, err
 	}
 
+	return res, nil
+}
+
+// cancelDeletion membatalkan jadwal penghapusan akun karena pemiliknya login kembali
+func (s *AuthService) cancelDeletion(ctx context.Context, user *domain.User) error {
+	cancelled, err := s.store.Users.CancelDeletion(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if !cancelled {
+		return nil
+	}
+
+	ctx = domain.WithActor(ctx, domain.Actor{UserID: user.ID})
+	recordAudit(ctx, s.store, domain.AuditUserDeleteCancel, domain.AuditTargetUser, user.ID, map[string]domain.AuditChange{
+		"deletion_scheduled_at": {From: user.DeletionScheduledAt, To: nil},
+	})
+
+	user.DeletionScheduledAt = nil
+
+	return s.store.Outbox.Enqueue(ctx, accountDeletionCancelledEmail(user))
+}
+
+// Refresh menukar refresh token dengan pasangan token baru. Refresh token lama
+// langsung hangus; jika token lama dipakai lagi, seluruh session dicabut.
+func (s *AuthService) Refresh(ctx context.Context, payload domain.RefreshTokenInput) (*domain.LoginResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	token, err := s.store.Sessions.ConsumeRefreshToken(ctx, helper.HashToken(payload.RefreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrTokenReused) {
+			log.Printf("Refresh token reuse detected for session %d, revoking", token.SessionID)
+			if err := s.store.Sessions.Revoke(ctx, token.SessionID); err != nil && !errors.Is(err, store.ErrNotFound) {
+				return nil, err
+			}
+			return nil, domain.ErrInvalidToken
+		}
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	session, err := s.store.Sessions.GetByID(ctx, token.SessionID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	// Role dibaca ulang supaya perubahan role ikut terbawa saat refresh
+	user, err := s.store.Users.GetByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, session, user.Role)
+}
+
+// Logout mencabut session beserta access token yang sedang dipakai
+func (s *AuthService) Logout(ctx context.Context, claims *domain.TokenClaims) error {
+	if err := s.store.Sessions.Revoke(ctx, claims.SessionID); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	return s.store.Sessions.RevokeToken(ctx, claims.TokenID, claims.ExpiresAt)
+}
+
+// ForgotPassword mengirim link reset ke email user. Email yang tidak terdaftar
+// tidak menghasilkan error supaya endpoint tidak bisa dipakai menebak akun.
+func (s *AuthService) ForgotPassword(ctx context.Context, payload domain.ForgotPasswordInput) error {
+	if err := s.validator.Struct(payload); err != nil {
+		return err
+	}
+
+	user, err := s.store.Users.GetByEmail(ctx, payload.Email)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := createUserToken(ctx, s.store, user.ID, domain.TokenPurposePasswordReset, s.config.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := linkWithToken(s.config.PasswordResetURL, token)
+
+	return s.store.Outbox.Enqueue(ctx, passwordResetEmail(user, link, s.config.PasswordResetTTL.String()))
+}
+
+// ResetPassword mengganti password memakai token dari email lalu mengeluarkan semua session
+func (s *AuthService) ResetPassword(ctx context.Context, payload domain.ResetPasswordInput) error {
+	if err := s.validator.Struct(payload); err != nil {
+		return err
+	}
+
+	token, err := s.store.Tokens.Consume(ctx, domain.TokenPurposePasswordReset, helper.HashToken(payload.Token))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+
+	user, err := s.store.Users.GetByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.Users.UpdatePassword(ctx, user.ID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	if err := s.store.Sessions.RevokeAllForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditPasswordReset, domain.AuditTargetUser, user.ID, nil)
+
+	return s.store.Outbox.Enqueue(ctx, passwordChangedEmail(user))
+}
+
+func (s *AuthService) VerifyEmail(ctx context.Context, payload domain.VerifyEmailInput) error {
+	if err := s.validator.Struct(payload); err != nil {
+		return err
+	}
+
+	token, err := s.store.Tokens.Consume(ctx, domain.TokenPurposeEmailVerification, helper.HashToken(payload.Token))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+
+	if err := s.store.Users.MarkEmailVerified(ctx, token.UserID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return domain.ErrInvalidToken
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ResendVerification mengirim ulang link verifikasi. Sama seperti ForgotPassword,
+// email yang tidak terdaftar atau sudah terverifikasi tidak menghasilkan error.
+func (s *AuthService) ResendVerification(ctx context.Context, payload domain.ResendVerificationInput) error {
+	if err := s.validator.Struct(payload); err != nil {
+		return err
+	}
+
+	user, err := s.store.Users.GetByEmail(ctx, payload.Email)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return sendVerificationEmail(ctx, s.store, s.config, user)
+}
+
+// UnlockAccount menghapus lockout akun, dipakai oleh admin
+func (s *AuthService) UnlockAccount(ctx context.Context, userID int64) error {
+	user, err := s.store.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.LoginAttempts.Reset(ctx, accountAttemptKey(user.Email)); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditUserUnlock, domain.AuditTargetUser, userID, nil)
+
+	return nil
+}
+
+// Authenticate memvalidasi access token dan memastikan session maupun jti-nya belum dicabut
+func (s *AuthService) Authenticate(ctx context.Context, tokenStr string) (*domain.TokenClaims, error) {
+	claims, err := helper.ValidateToken(tokenStr)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	revoked, err := s.store.Sessions.IsRevoked(ctx, claims.SessionID, claims.TokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if revoked {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if err := s.store.Sessions.Touch(ctx, claims.SessionID); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (s *AuthService) issueTokens(ctx context.Context, session *domain.Session, role domain.Role) (*domain.LoginResponse, error) {
+	refreshToken, refreshHash, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Sessions.CreateRefreshToken(ctx, &domain.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: refreshHash,
+	}); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := helper.GenerateToken(domain.TokenClaims{
+		UserID:    session.UserID,
+		SessionID: session.ID,
+		Role:      role,
+		TokenID:   uuid.NewString(),
+		ExpiresAt: time.Now().Add(s.config.AccessTokenTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return &domain.LoginResponse{
-		Token: token,
-		Type:  "Bearer",
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		Type:         "Bearer",
+		ExpiresIn:    int64(s.config.AccessTokenTTL.Seconds()),
 	}, nil
 }
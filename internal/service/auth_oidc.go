@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/MyFirstGo/internal/store"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const oidcAuthRequestTTL = 10 * time.Minute
+
+// StartOIDC menyiapkan state, nonce dan PKCE verifier lalu mengembalikan URL login provider
+func (s *AuthService) StartOIDC(ctx context.Context, providerName string) (*domain.OIDCAuthorization, error) {
+	provider, ok := s.config.OIDCProviders[providerName]
+	if !ok {
+		return nil, domain.ErrUnknownProvider
+	}
+
+	state, stateHash, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, _, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, _, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedVerifier, err := helper.Encrypt([]byte(verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.Identities.CreateAuthRequest(ctx, &domain.OIDCAuthRequest{
+		StateHash:    stateHash,
+		Provider:     providerName,
+		CodeVerifier: encryptedVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oidcAuthRequestTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.OIDCAuthorization{URL: authURL, State: state}, nil
+}
+
+// LoginOIDC menyelesaikan callback provider. User dicari lewat identity yang sudah
+// terhubung, lalu lewat email terverifikasi, dan dibuat baru jika belum ada.
+func (s *AuthService) LoginOIDC(ctx context.Context, payload domain.OIDCCallbackInput) (*domain.LoginResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	provider, ok := s.config.OIDCProviders[payload.Provider]
+	if !ok {
+		return nil, domain.ErrUnknownProvider
+	}
+
+	req, err := s.store.Identities.ConsumeAuthRequest(ctx, helper.HashToken(payload.State))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if req.Provider != payload.Provider {
+		return nil, domain.ErrInvalidToken
+	}
+
+	verifier, err := helper.Decrypt(req.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	external, err := provider.Exchange(ctx, payload.Code, string(verifier), req.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveOIDCUser(ctx, payload.Provider, external)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return s.issueChallenge(user)
+	}
+
+	return s.completeLogin(ctx, user, payload.UserAgent, payload.IPAddress)
+}
+
+func (s *AuthService) resolveOIDCUser(ctx context.Context, providerName string, external *domain.ExternalIdentity) (*domain.User, error) {
+	identity, err := s.store.Identities.GetByProviderSubject(ctx, providerName, external.Subject)
+	if err == nil {
+		if err := s.store.Identities.TouchLogin(ctx, identity.ID); err != nil {
+			return nil, err
+		}
+		return s.store.Users.GetByID(ctx, identity.UserID)
+	}
+
+	if !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+
+	// Akun hanya boleh dihubungkan lewat email yang sudah diverifikasi provider
+	if external.Email == "" || !external.EmailVerified {
+		return nil, domain.ErrEmailNotVerified
+	}
+
+	identity = &domain.UserIdentity{
+		Provider: providerName,
+		Subject:  external.Subject,
+		Email:    external.Email,
+	}
+
+	user, err := s.store.Users.GetByEmail(ctx, external.Email)
+	if err == nil {
+		// Akun lokal yang emailnya belum diverifikasi bisa saja didaftarkan orang lain
+		// dengan email korban, jadi tidak dihubungkan otomatis
+		if user.EmailVerifiedAt == nil {
+			return nil, domain.ErrIdentityConflict
+		}
+
+		identity.UserID = user.ID
+		if err := s.store.Identities.Create(ctx, identity); err != nil {
+			return nil, err
+		}
+
+		log.Printf("Linked %s identity to user ID: %d", providerName, user.ID)
+		return user, nil
+	}
+
+	if !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+
+	return s.createOIDCUser(ctx, external, identity)
+}
+
+func (s *AuthService) createOIDCUser(ctx context.Context, external *domain.ExternalIdentity, identity *domain.UserIdentity) (*domain.User, error) {
+	// User dari provider eksternal tidak punya password, isi dengan password acak
+	// yang tidak pernah diketahui siapa pun. User bisa memakai reset password nanti.
+	randomPassword, _, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	username, err := usernameFromEmail(external.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		Username: username,
+		Email:    external.Email,
+		Password: string(hashedPassword),
+	}
+
+	if err := s.store.Identities.CreateWithUser(ctx, user, identity); err != nil {
+		if helper.IsDuplicateKeyError(err) {
+			return nil, domain.ErrDuplicateEmail
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+// usernameFromEmail membuat username unik dari bagian depan email, misal budi_3fa2c1
+func usernameFromEmail(email string) (string, error) {
+	local, _, _ := strings.Cut(email, "@")
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(local) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '.' {
+			b.WriteRune(r)
+		}
+	}
+
+	base := b.String()
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 30 {
+		base = base[:30]
+	}
+
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+
+	return base + "_" + hex.EncodeToString(suffix), nil
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/MyFirstGo/internal/mapper"
+	"github.com/MyFirstGo/internal/store"
+)
+
+const (
+	exportBatchSize   = 2
+	exportLease       = 10 * time.Minute
+	exportMaxAttempts = 3
+	exportAuditPage   = 500
+)
+
+type DataExportService struct {
+	store   store.Storage
+	storage domain.FileStorage
+	config  Config
+}
+
+// Request mengantrekan export baru, diproses di background oleh worker
+func (s *DataExportService) Request(ctx context.Context, userID int64) (*domain.DataExport, error) {
+	export := &domain.DataExport{UserID: userID}
+	if err := s.store.DataExports.Create(ctx, export); err != nil {
+		if helper.IsDuplicateKeyError(err) {
+			return nil, domain.ErrExportInProgress
+		}
+		return nil, err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditDataExport, domain.AuditTargetUser, userID, nil)
+
+	return export, nil
+}
+
+// Get mengembalikan status export, beserta link download sementara jika sudah selesai
+func (s *DataExportService) Get(ctx context.Context, userID, exportID int64) (*domain.DataExport, error) {
+	export, err := s.store.DataExports.GetForUser(ctx, userID, exportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Status == domain.ExportCompleted && export.ObjectKey != nil && time.Now().Before(*export.ExpiresAt) {
+		url, err := s.storage.PresignedURL(ctx, *export.ObjectKey, s.config.DataExportLinkTTL)
+		if err != nil {
+			return nil, err
+		}
+		export.DownloadURL = url
+	}
+
+	return export, nil
+}
+
+func (s *DataExportService) ProcessPending(ctx context.Context) error {
+	exports, err := s.store.DataExports.Claim(ctx, exportBatchSize, exportLease)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if err := s.process(ctx, export); err != nil {
+			log.Printf("Failed to build data export %d (attempt %d): %v", export.ID, export.Attempts, err)
+			if err := s.store.DataExports.MarkFailed(ctx, export.ID, err, export.Attempts < exportMaxAttempts); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// PurgeExpired menghapus file export yang sudah melewati masa simpan
+func (s *DataExportService) PurgeExpired(ctx context.Context) error {
+	exports, err := s.store.DataExports.ListExpired(ctx, 50)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if export.ObjectKey != nil {
+			if err := s.storage.Delete(ctx, *export.ObjectKey); err != nil {
+				return err
+			}
+		}
+
+		if err := s.store.DataExports.MarkExpired(ctx, export.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *DataExportService) process(ctx context.Context, export *domain.DataExport) error {
+	user, err := s.store.Users.GetByID(ctx, export.UserID)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := s.writeArchive(ctx, buf, user); err != nil {
+		return err
+	}
+
+	objectName := fmt.Sprintf("exports/%d/nutritrack-export-%d.zip", user.ID, export.ID)
+	objectKey, err := s.storage.Upload(ctx, objectName, buf, int64(buf.Len()), "application/zip")
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.DataExports.MarkCompleted(ctx, export.ID, objectKey, time.Now().Add(s.config.DataExportTTL)); err != nil {
+		return err
+	}
+
+	return s.store.Outbox.Enqueue(ctx, dataExportReadyEmail(user, s.config.DataExportTTL.String()))
+}
+
+func (s *DataExportService) writeArchive(ctx context.Context, w io.Writer, user *domain.User) error {
+	archive := zip.NewWriter(w)
+
+	if err := writeJSONFile(archive, "profile.json", mapper.UserToUserResponse(user)); err != nil {
+		return err
+	}
+
+	diaries, err := s.store.Diary.GetAllByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := writeJSONFile(archive, "diaries.json", diaries); err != nil {
+		return err
+	}
+
+	if err := writeCSVFile(archive, "diaries.csv", diaryRows(diaries)); err != nil {
+		return err
+	}
+
+	foods, err := s.store.Foods.ListByOwner(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := writeJSONFile(archive, "custom_foods.json", foods); err != nil {
+		return err
+	}
+
+	weights, err := s.weightHistory(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	if err := writeCSVFile(archive, "weight_history.csv", weights); err != nil {
+		return err
+	}
+
+	sessions, err := s.store.Sessions.ListActiveByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := writeJSONFile(archive, "sessions.json", sessions); err != nil {
+		return err
+	}
+
+	apiKeys, err := s.store.APIKeys.ListByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := writeJSONFile(archive, "api_keys.json", apiKeys); err != nil {
+		return err
+	}
+
+	identities, err := s.store.Identities.ListByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := writeJSONFile(archive, "linked_accounts.json", identities); err != nil {
+		return err
+	}
+
+	if err := s.writeAvatar(ctx, archive, user.ID); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// weightHistory disusun dari audit log karena berat badan hanya disimpan nilai terakhirnya
+func (s *DataExportService) weightHistory(ctx context.Context, user *domain.User) ([][]string, error) {
+	rows := [][]string{{"recorded_at", "weight"}}
+
+	filter := domain.AuditFilter{
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &user.ID,
+		Page:       domain.PageRequest{Limit: exportAuditPage},
+	}
+
+	var history [][]string
+	for {
+		events, err := s.store.Audit.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range events.Data {
+			change, ok := e.Changes["weight"]
+			if !ok || change.To == nil {
+				continue
+			}
+			history = append(history, []string{e.CreatedAt.Format(time.RFC3339), fmt.Sprint(change.To)})
+		}
+
+		if events.NextCursor == nil {
+			break
+		}
+		filter.Page.Cursor = events.NextCursor
+	}
+
+	// Audit log diurutkan dari yang terbaru, export dari yang terlama
+	for i := len(history) - 1; i >= 0; i-- {
+		rows = append(rows, history[i])
+	}
+
+	if len(history) == 0 && user.Weight != nil {
+		rows = append(rows, []string{user.UpdatedAt, strconv.FormatFloat(*user.Weight, 'f', -1, 64)})
+	}
+
+	return rows, nil
+}
+
+func (s *DataExportService) writeAvatar(ctx context.Context, archive *zip.Writer, userID int64) error {
+	avatar, err := s.store.Users.GetAvatar(ctx, userID)
+	if err != nil || avatar == nil {
+		return err
+	}
+
+	obj, err := s.storage.Download(ctx, *avatar)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+
+	f, err := archive.Create("avatar" + path.Ext(*avatar))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, obj)
+	return err
+}
+
+func diaryRows(diaries []*domain.FoodDiary) [][]string {
+	rows := [][]string{{"id", "consumed_at", "meal_type", "food_id", "food_name", "amount_consumed"}}
+
+	for _, d := range diaries {
+		foodName := ""
+		if d.FoodName != nil {
+			foodName = *d.FoodName
+		}
+
+		rows = append(rows, []string{
+			strconv.FormatInt(d.ID, 10),
+			d.ConsumedAt.Format(time.RFC3339),
+			d.MealType,
+			strconv.FormatInt(d.FoodID, 10),
+			foodName,
+			strconv.FormatFloat(d.AmountConsumed, 'f', -1, 64),
+		})
+	}
+
+	return rows
+}
+
+func writeJSONFile(archive *zip.Writer, name string, data any) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+func writeCSVFile(archive *zip.Writer, name string, rows [][]string) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+
+	return csv.NewWriter(f).WriteAll(rows)
+}
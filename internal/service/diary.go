@@ -15,6 +15,31 @@ import (
 type DiaryService struct {
 	store     store.Storage
 	validator validator.Validate
+	config    Config
+}
+
+// requireVerified memblokir penulisan diary sebelum email diverifikasi jika policy mengharuskan
+func (s *DiaryService) requireVerified(ctx context.Context, userID int64) error {
+	if s.config.EmailVerification != VerifyDiary {
+		return nil
+	}
+
+	user, err := s.store.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return domain.ErrEmailNotVerified
+	}
+
+	return nil
+}
+
+func (s *DiaryService) List(ctx context.Context, filter domain.DiaryFilter) (*domain.Page[*domain.FoodDiary], error) {
+	filter.Page = normalizePage(filter.Page)
+
+	return s.store.Diary.List(ctx, filter)
 }
 
 func (s *DiaryService) GetSummaryByUserId(ctx context.Context, userID int64, date time.Time) (*domain.DailySummary, error) {
@@ -88,13 +113,48 @@ func (s *DiaryService) Create(ctx context.Context, input *domain.DiaryCreateInpu
 		return nil, err
 	}
 
+	if err := s.requireVerified(ctx, input.UserID); err != nil {
+		return nil, err
+	}
+
+	// Food private milik user lain diperlakukan seperti tidak ada
+	food, err := s.store.Foods.GetByID(ctx, input.FoodID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if !(domain.FoodViewer{UserID: input.UserID}).CanView(food) {
+		return nil, domain.ErrNotFound
+	}
+
+	var portion *domain.FoodPortion
+	if input.PortionID != nil {
+		input.AmountConsumed, portion, err = s.portionAmount(ctx, food, *input.PortionID, *input.Quantity)
+	} else {
+		input.AmountConsumed, err = toServingUnit(food, input.AmountConsumed, input.Unit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	if input.ConsumedAt.IsZero() {
 		input.ConsumedAt = time.Now()
 	}
 
 	diary := mapper.CreateDiaryInputToFoodDiary(input)
+	if portion != nil {
+		diary.PortionName = &portion.Name
+	}
 
-	err := s.store.Diary.Create(ctx, diary)
+	// Entry dipasangkan ke revisi food yang dipakai untuk menghitung jumlahnya
+	if food.Revision > 0 {
+		diary.FoodRevision = &food.Revision
+	}
+
+	err = s.store.Diary.Create(ctx, diary)
 	if err != nil {
 		return nil, err
 	}
@@ -102,18 +162,87 @@ func (s *DiaryService) Create(ctx context.Context, input *domain.DiaryCreateInpu
 	return diary, nil
 }
 
+// portionAmount mengubah quantity x portion menjadi amount_consumed dalam serving_unit food.
+// Food dengan serving berupa porsi (resep) tidak bisa memakai portion berbasis berat.
+func (s *DiaryService) portionAmount(ctx context.Context, food *domain.Food, portionID int64, quantity float64) (float64, *domain.FoodPortion, error) {
+	if err := portionsSupported(food); err != nil {
+		return 0, nil, err
+	}
+
+	portion, err := s.store.Foods.GetPortion(ctx, food.ID, portionID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return 0, nil, domain.ErrInvalidPortion
+		}
+		return 0, nil, err
+	}
+
+	amount, err := toServingUnit(food, quantity*portion.Grams, "g")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return amount, portion, nil
+}
+
 func (s *DiaryService) Update(ctx context.Context, userID int64, input *domain.DiaryUpdateInput) (*domain.FoodDiary, error) {
 	if err := s.validator.Struct(input); err != nil {
 		return nil, err
 	}
 
+	if err := s.requireVerified(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	diary, err := s.GetDiaryWithUserId(ctx, userID, input.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	if input.AmountConsumed != nil {
-		diary.AmountConsumed = *input.AmountConsumed
+		amount := *input.AmountConsumed
+		if input.Unit != "" {
+			food, err := s.store.Foods.GetByID(ctx, diary.FoodID)
+			if err != nil {
+				return nil, err
+			}
+
+			if amount, err = toServingUnit(food, amount, input.Unit); err != nil {
+				return nil, err
+			}
+			// Jumlah sekarang dalam serving unit revisi terbaru, pin entry ke revisi itu
+			diary.FoodRevision = &food.Revision
+		}
+
+		diary.AmountConsumed = amount
+		diary.PortionID, diary.Quantity, diary.PortionName = nil, nil, nil
+	}
+
+	// Portion atau quantity bisa diubah sendiri-sendiri, yang tidak dikirim memakai nilai lama
+	if input.PortionID != nil || input.Quantity != nil {
+		if input.PortionID != nil {
+			diary.PortionID = input.PortionID
+		}
+		if input.Quantity != nil {
+			diary.Quantity = input.Quantity
+		}
+
+		if diary.PortionID == nil || diary.Quantity == nil {
+			return nil, domain.ErrInvalidPortion
+		}
+
+		food, err := s.store.Foods.GetByID(ctx, diary.FoodID)
+		if err != nil {
+			return nil, err
+		}
+
+		amount, portion, err := s.portionAmount(ctx, food, *diary.PortionID, *diary.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		diary.AmountConsumed = amount
+		diary.PortionName = &portion.Name
+		diary.FoodRevision = &food.Revision
 	}
 
 	if input.ConsumedAt != nil {
@@ -133,6 +262,10 @@ func (s *DiaryService) Update(ctx context.Context, userID int64, input *domain.D
 }
 
 func (s *DiaryService) Delete(ctx context.Context, userID, diaryID int64) error {
+	if err := s.requireVerified(ctx, userID); err != nil {
+		return err
+	}
+
 	_, err := s.GetDiaryWithUserId(ctx, userID, diaryID)
 	if err != nil {
 		return err
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+func linkWithToken(baseURL, token string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return baseURL + "?token=" + url.QueryEscape(token)
+	}
+
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+func passwordResetEmail(user *domain.User, link string, ttl string) domain.Email {
+	return domain.Email{
+		To:      user.Email,
+		Subject: "Reset password NutriTrack",
+		Body: fmt.Sprintf(`Halo %s,
+
+Kami menerima permintaan untuk mereset password akun NutriTrack kamu.
+Buka link berikut untuk membuat password baru (berlaku %s):
+
+%s
+
+Jika kamu tidak merasa meminta reset password, abaikan email ini.
+`, user.Username, ttl, link),
+	}
+}
+
+func verificationEmail(user *domain.User, link string, ttl string) domain.Email {
+	return domain.Email{
+		To:      user.Email,
+		Subject: "Verifikasi email NutriTrack",
+		Body: fmt.Sprintf(`Halo %s,
+
+Terima kasih sudah mendaftar di NutriTrack.
+Buka link berikut untuk memverifikasi email kamu (berlaku %s):
+
+%s
+`, user.Username, ttl, link),
+	}
+}
+
+func dataExportReadyEmail(user *domain.User, ttl string) domain.Email {
+	return domain.Email{
+		To:      user.Email,
+		Subject: "Export data NutriTrack kamu sudah siap",
+		Body: fmt.Sprintf(`Halo %s,
+
+Export data akun NutriTrack yang kamu minta sudah selesai dibuat.
+Buka menu pengaturan akun di aplikasi untuk mengunduhnya. File akan dihapus otomatis setelah %s.
+`, user.Username, ttl),
+	}
+}
+
+func passwordChangedEmail(user *domain.User) domain.Email {
+	return domain.Email{
+		To:      user.Email,
+		Subject: "Password NutriTrack kamu telah diubah",
+		Body: fmt.Sprintf(`Halo %s,
+
+Password akun NutriTrack kamu baru saja diubah dan semua sesi login telah dikeluarkan.
+Jika ini bukan kamu, segera hubungi tim kami.
+`, user.Username),
+	}
+}
+
+func accountDeletionScheduledEmail(user *domain.User, at string) domain.Email {
+	return domain.Email{
+		To:      user.Email,
+		Subject: "Akun NutriTrack kamu akan dihapus",
+		Body: fmt.Sprintf(`Halo %s,
+
+Akun NutriTrack kamu dijadwalkan untuk dihapus permanen pada %s, termasuk seluruh diary dan foto profil.
+Semua sesi login telah dikeluarkan.
+
+Jika kamu berubah pikiran, cukup login kembali sebelum waktu tersebut untuk membatalkan penghapusan.
+`, user.Username, at),
+	}
+}
+
+func accountDeletionCancelledEmail(user *domain.User) domain.Email {
+	return domain.Email{
+		To:      user.Email,
+		Subject: "Penghapusan akun NutriTrack dibatalkan",
+		Body: fmt.Sprintf(`Halo %s,
+
+Kamu login kembali ke NutriTrack, jadi jadwal penghapusan akun kamu telah dibatalkan.
+Jika ini bukan kamu, segera ganti password akun kamu.
+`, user.Username),
+	}
+}
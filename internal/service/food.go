@@ -2,11 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
 	"github.com/MyFirstGo/internal/mapper"
 	"github.com/MyFirstGo/internal/store"
+	"github.com/MyFirstGo/pkg/barcode"
 	"github.com/MyFirstGo/pkg/converter"
 	"github.com/go-playground/validator/v10"
 )
@@ -16,7 +21,7 @@ type FoodService struct {
 	validator validator.Validate
 }
 
-func (s *FoodService) validateFoodNutrients(food domain.Food) error {
+func validateFoodNutrients(food domain.Food) error {
 	servingSize, servingUnit := 100.00, "g"
 
 	if food.ServingSize != nil {
@@ -27,7 +32,18 @@ func (s *FoodService) validateFoodNutrients(food domain.Food) error {
 		servingUnit = *food.ServingUnit
 	}
 
-	totalWeightInGrams := converter.ToGrams(servingSize, servingUnit)
+	// Serving berupa porsi (resep) tidak punya berat untuk dibandingkan
+	if servingUnit == domain.ServingUnitServing {
+		return nil
+	}
+
+	totalWeightInGrams, err := converter.ToGrams(servingSize, servingUnit, foodDensity(&food))
+	if errors.Is(err, converter.ErrIncompatibleUnit) {
+		// Serving volume tanpa density tidak punya berat untuk dibandingkan
+		totalWeightInGrams = math.Inf(1)
+	} else if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrInvalidUnit, servingUnit)
+	}
 
 	for _, n := range food.Nutrients {
 		if n.Amount < 0 {
@@ -35,11 +51,13 @@ func (s *FoodService) validateFoodNutrients(food domain.Food) error {
 				n.Name, n.Amount, n.Unit)
 		}
 
-		if n.Unit == "kcal" {
+		// Nutrient selain massa (kcal, IU) tidak bisa dibandingkan dengan berat serving
+		unit, err := converter.ParseUnit(n.Unit)
+		if err != nil || unit.Dimension != converter.Mass {
 			continue
 		}
 
-		nutrientInGrams := converter.ToGrams(n.Amount, n.Unit)
+		nutrientInGrams := n.Amount * unit.Factor
 
 		if nutrientInGrams > totalWeightInGrams {
 			return fmt.Errorf("nilai nutrisi %s (%.2fg) tidak boleh lebih dari total berat saji (%.2fg)!",
@@ -50,8 +68,76 @@ func (s *FoodService) validateFoodNutrients(food domain.Food) error {
 	return nil
 }
 
-func (s *FoodService) Search(ctx context.Context, filter domain.FoodFilter) ([]*domain.Food, error) {
-	return s.store.Foods.Search(ctx, filter)
+func (s *FoodService) Search(ctx context.Context, filter domain.FoodFilter) (*domain.Page[*domain.Food], error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	filter.Page = normalizePage(filter.Page)
+
+	if filter.Basis == "" {
+		filter.Basis = domain.NutrientBasis100g
+	}
+
+	if filter.Sort.Field == "" {
+		filter.Sort.Field = domain.FoodSortName
+		if filter.Query != "" {
+			filter.Sort.Field = domain.FoodSortRelevance
+		}
+	}
+
+	if err := s.validator.Struct(filter); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNutrientSlugs(ctx, filter); err != nil {
+		return nil, err
+	}
+
+	page, err := s.store.Foods.Search(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, food := range page.Data {
+		localizeFood(ctx, food)
+	}
+
+	return page, nil
+}
+
+// checkNutrientSlugs memastikan semua slug di filter dan sort dikenal, supaya
+// typo di query string menghasilkan error dan bukan hasil kosong
+func (s *FoodService) checkNutrientSlugs(ctx context.Context, filter domain.FoodFilter) error {
+	slugs := make([]string, 0, len(filter.Nutrients)+1)
+	for _, r := range filter.Nutrients {
+		slugs = append(slugs, r.Slug)
+	}
+
+	if filter.Sort.Field != domain.FoodSortName && filter.Sort.Field != domain.FoodSortRelevance {
+		slugs = append(slugs, filter.Sort.Field)
+	} else if filter.Sort.Per100kcal {
+		return fmt.Errorf("%w: %s", domain.ErrUnknownNutrient, filter.Sort.Field)
+	}
+
+	if len(slugs) == 0 {
+		return nil
+	}
+
+	nutrients, err := s.store.Foods.ListNutrients(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(nutrients))
+	for _, n := range nutrients {
+		known[n.Slug] = true
+	}
+
+	for _, slug := range slugs {
+		if !known[slug] {
+			return fmt.Errorf("%w: %s", domain.ErrUnknownNutrient, slug)
+		}
+	}
+
+	return nil
 }
 
 func (s *FoodService) GetPaginated(ctx context.Context, page, size int) ([]*domain.Food, error) {
@@ -69,29 +155,207 @@ func (s *FoodService) GetPaginated(ctx context.Context, page, size int) ([]*doma
 	return s.store.Foods.GetPaginated(ctx, size, offset)
 }
 
-func (s *FoodService) GetByID(ctx context.Context, id int64) (*domain.Food, error) {
-	return s.store.Foods.GetByID(ctx, id)
+func (s *FoodService) GetByID(ctx context.Context, viewer domain.FoodViewer, id int64) (*domain.Food, error) {
+	food, err := getVisibleFood(ctx, s.store, viewer, id)
+	if err != nil {
+		return nil, err
+	}
+
+	localizeFood(ctx, food)
+
+	return food, nil
 }
 
-func (s *FoodService) Create(ctx context.Context, input *domain.CreateFoodInput) (*domain.Food, error) {
+// getVisibleFood mengambil food, food yang tidak boleh dilihat viewer dianggap tidak ada
+// supaya keberadaan food private user lain tidak bocor
+func getVisibleFood(ctx context.Context, st store.Storage, viewer domain.FoodViewer, id int64) (*domain.Food, error) {
+	food, err := st.Foods.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !viewer.CanView(food) {
+		return nil, store.ErrNotFound
+	}
+
+	return food, nil
+}
+
+// getModifiableFood mengambil food yang boleh diubah atau dihapus viewer
+func getModifiableFood(ctx context.Context, st store.Storage, viewer domain.FoodViewer, id int64) (*domain.Food, error) {
+	food, err := getVisibleFood(ctx, st, viewer, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !viewer.CanModify(food) {
+		return nil, domain.ErrFoodForbidden
+	}
+
+	return food, nil
+}
+
+func (s *FoodService) GetByBarcode(ctx context.Context, viewer domain.FoodViewer, code string) (*domain.Food, error) {
+	gtin, err := barcode.Normalize(code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidBarcode, err)
+	}
+
+	food, err := s.store.Foods.GetByBarcode(ctx, gtin)
+	if err != nil {
+		return nil, err
+	}
+
+	if !viewer.CanView(food) {
+		return nil, store.ErrNotFound
+	}
+
+	localizeFood(ctx, food)
+
+	return food, nil
+}
+
+// localizeFood mengganti Name dengan nama sesuai Accept-Language (urut prioritas),
+// fallback ke foods.name yang berbahasa Inggris
+func localizeFood(ctx context.Context, food *domain.Food) {
+	food.Locale = domain.DefaultLocale
+
+	for _, locale := range domain.LocalesFrom(ctx) {
+		if locale == domain.DefaultLocale {
+			return
+		}
+
+		for _, n := range food.Names {
+			if !n.Alias && n.Locale == locale {
+				food.Name = n.Name
+				food.Locale = locale
+				return
+			}
+		}
+	}
+}
+
+// normalizeNames menyeragamkan locale ke bahasa dasar ("id-ID" jadi "id") dan membuang duplikat.
+// Nama tampilan hanya satu per bahasa, dan nama bahasa Inggris tetap di foods.name.
+func normalizeNames(names []domain.FoodName) ([]domain.FoodName, error) {
+	res := make([]domain.FoodName, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	display := make(map[string]bool, len(names))
+
+	for _, n := range names {
+		n.Locale = helper.BaseLocale(n.Locale)
+		n.Name = strings.TrimSpace(n.Name)
+		if n.Name == "" {
+			continue
+		}
+
+		if !n.Alias {
+			if n.Locale == domain.DefaultLocale || display[n.Locale] {
+				return nil, fmt.Errorf("%w: %s", domain.ErrDuplicateFoodName, n.Locale)
+			}
+			display[n.Locale] = true
+		}
+
+		key := n.Locale + "\x00" + strings.ToLower(n.Name)
+		if !seen[key] {
+			seen[key] = true
+			res = append(res, n)
+		}
+	}
+
+	return res, nil
+}
+
+// normalizeBarcodes memvalidasi check digit dan mengubah semua barcode ke GTIN-14, duplikat dibuang
+func normalizeBarcodes(codes []string) ([]string, error) {
+	res := make([]string, 0, len(codes))
+	seen := make(map[string]bool, len(codes))
+
+	for _, code := range codes {
+		gtin, err := barcode.Normalize(code)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", domain.ErrInvalidBarcode, code, err)
+		}
+
+		if !seen[gtin] {
+			seen[gtin] = true
+			res = append(res, gtin)
+		}
+	}
+
+	return res, nil
+}
+
+func (s *FoodService) Create(ctx context.Context, viewer domain.FoodViewer, input *domain.CreateFoodInput) (*domain.Food, error) {
 
 	if err := s.validator.Struct(input); err != nil {
 		return nil, err
 	}
 
+	// Hanya editor katalog yang boleh langsung menambah food publik
+	visibility := input.Visibility
+	if visibility == "" {
+		visibility = domain.FoodVisibilityPrivate
+		if viewer.Editor {
+			visibility = domain.FoodVisibilityPublic
+		}
+	}
+
+	if visibility == domain.FoodVisibilityPublic && !viewer.Editor {
+		return nil, domain.ErrFoodForbidden
+	}
+
+	if visibility != domain.FoodVisibilityPublic && len(input.Barcodes) > 0 {
+		return nil, domain.ErrPrivateBarcode
+	}
+
 	servingSize, servingUnit := float64(100), "g"
 
 	if input.ServingSize == nil {
 		input.ServingSize = &servingSize
 	}
 
-	if input.ServingUnit == nil {
+	if input.ServingUnit == nil || *input.ServingUnit == "" {
 		input.ServingUnit = &servingUnit
 	}
 
+	unit, err := normalizeServingUnit(*input.ServingUnit)
+	if err != nil {
+		return nil, err
+	}
+	input.ServingUnit = &unit
+
+	portions, err := normalizePortions(input.Portions)
+	if err != nil {
+		return nil, err
+	}
+
 	food := mapper.CreateFoodInputToFood(input)
+	food.Portions = portions
 
-	s.validateFoodNutrients(*food)
+	if len(portions) > 0 {
+		if err := portionsSupported(food); err != nil {
+			return nil, err
+		}
+	}
+	food.Visibility = visibility
+	if visibility != domain.FoodVisibilityPublic {
+		food.OwnerID = &viewer.UserID
+	}
+
+	barcodes, err := normalizeBarcodes(input.Barcodes)
+	if err != nil {
+		return nil, err
+	}
+	food.Barcodes = barcodes
+
+	names, err := normalizeNames(input.Names)
+	if err != nil {
+		return nil, err
+	}
+	food.Names = names
+
+	validateFoodNutrients(*food)
 
 	for _, n := range input.Nutrients {
 		food.Nutrients = append(food.Nutrients, domain.NutrientAmount{
@@ -103,19 +367,38 @@ func (s *FoodService) Create(ctx context.Context, input *domain.CreateFoodInput)
 	}
 
 	if err := s.store.Foods.Create(ctx, food); err != nil {
+		if helper.IsUniqueViolation(err, store.ConstraintFoodBarcode) {
+			return nil, domain.ErrBarcodeTaken
+		}
 		return nil, err
 	}
 
+	recordAudit(ctx, s.store, domain.AuditFoodCreate, domain.AuditTargetFood, food.ID, auditDiff(struct{}{}, food))
+
+	localizeFood(ctx, food)
+
 	return food, nil
 }
 
-func (s *FoodService) Update(ctx context.Context, id int64, input domain.UpdateFoodInput) (*domain.Food, error) {
+func (s *FoodService) Update(ctx context.Context, viewer domain.FoodViewer, id int64, input domain.UpdateFoodInput) (*domain.Food, error) {
 	// 1. Ambil data asli dari DB
-	food, err := s.store.Foods.GetByID(ctx, id)
+	food, err := getModifiableFood(ctx, s.store, viewer, id)
 	if err != nil {
 		return nil, err // Pastikan store return ErrNotFound jika tidak ada
 	}
 
+	before := *food
+
+	// Nutrient dan serving resep dihitung dari bahan, ubah lewat endpoint resep
+	computed := input.Nutrients != nil || input.ServingSize != nil || input.ServingUnit != nil
+	if computed {
+		if _, err := s.store.Recipes.GetByFoodID(ctx, id); err == nil {
+			return nil, domain.ErrRecipeComputed
+		} else if !errors.Is(err, store.ErrNotFound) {
+			return nil, err
+		}
+	}
+
 	// 2. Patching: Update field hanya jika user mengirimkan datanya (tidak nil)
 	if input.Name != nil {
 		food.Name = *input.Name
@@ -127,7 +410,21 @@ func (s *FoodService) Update(ctx context.Context, id int64, input domain.UpdateF
 		food.ServingSize = input.ServingSize
 	}
 	if input.ServingUnit != nil {
-		food.ServingUnit = input.ServingUnit
+		unit, err := normalizeServingUnit(*input.ServingUnit)
+		if err != nil {
+			return nil, err
+		}
+		food.ServingUnit = &unit
+	}
+	if input.Density != nil {
+		food.Density = input.Density
+	}
+
+	// Portion yang sudah ada harus tetap bisa diubah ke serving_unit yang baru
+	if (input.ServingUnit != nil || input.Density != nil) && len(food.Portions) > 0 {
+		if err := portionsSupported(food); err != nil {
+			return nil, err
+		}
 	}
 
 	// 3. Logic Update Nutrients (Replace strategy)
@@ -142,19 +439,266 @@ func (s *FoodService) Update(ctx context.Context, id int64, input domain.UpdateF
 		food.Nutrients = newNutrients
 	}
 
+	if input.Barcodes != nil {
+		if food.Visibility != domain.FoodVisibilityPublic && len(*input.Barcodes) > 0 {
+			return nil, domain.ErrPrivateBarcode
+		}
+
+		barcodes, err := normalizeBarcodes(*input.Barcodes)
+		if err != nil {
+			return nil, err
+		}
+		food.Barcodes = barcodes
+	}
+
+	if input.Names != nil {
+		names, err := normalizeNames(*input.Names)
+		if err != nil {
+			return nil, err
+		}
+		food.Names = names
+	}
+
 	// 4. Jalankan validasi bisnis (misal: kalori tidak boleh negatif)
-	if err := s.validateFoodNutrients(*food); err != nil {
+	if err := validateFoodNutrients(*food); err != nil {
 		return nil, err
 	}
 
 	// 5. Simpan ke Store
 	if err := s.store.Foods.Update(ctx, food); err != nil {
+		if helper.IsUniqueViolation(err, store.ConstraintFoodBarcode) {
+			return nil, domain.ErrBarcodeTaken
+		}
 		return nil, err
 	}
 
+	recordAudit(ctx, s.store, domain.AuditFoodUpdate, domain.AuditTargetFood, food.ID, auditDiff(before, food))
+
+	if computed {
+		refreshRecipes(ctx, s.store, food.ID)
+	}
+
+	localizeFood(ctx, food)
+
 	return food, nil
 }
 
-func (s *FoodService) Delete(ctx context.Context, id int64) error {
-	return s.store.Foods.Delete(ctx, id)
+// normalizeServingUnit mengubah serving_unit ke simbol baku pkg/converter.
+// Satuan "serving" hanya dipakai resep dan tidak bisa diisi langsung.
+func normalizeServingUnit(unit string) (string, error) {
+	u, err := converter.ParseUnit(unit)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", domain.ErrInvalidUnit, unit)
+	}
+
+	return u.Symbol, nil
+}
+
+func foodServingUnit(food *domain.Food) string {
+	if food.ServingUnit == nil {
+		return "g"
+	}
+	return *food.ServingUnit
+}
+
+func foodDensity(food *domain.Food) float64 {
+	if food.Density == nil {
+		return 0
+	}
+	return *food.Density
+}
+
+// portionsSupported: berat portion harus bisa diubah ke serving_unit food. Serving berupa
+// porsi (resep) tidak punya berat, serving volume butuh density.
+func portionsSupported(food *domain.Food) error {
+	unit := foodServingUnit(food)
+	if unit == domain.ServingUnitServing {
+		return domain.ErrInvalidPortion
+	}
+
+	if u, err := converter.ParseUnit(unit); err == nil && u.Dimension == converter.Volume && food.Density == nil {
+		return domain.ErrDensityRequired
+	}
+
+	return nil
+}
+
+// toServingUnit mengubah amount dalam unit ke serving_unit food, unit kosong berarti sudah
+// dalam serving_unit
+func toServingUnit(food *domain.Food, amount float64, unit string) (float64, error) {
+	servingUnit := foodServingUnit(food)
+	if unit == "" || unit == servingUnit {
+		return amount, nil
+	}
+
+	if servingUnit == domain.ServingUnitServing {
+		return 0, fmt.Errorf("%w: %s", domain.ErrIncompatibleUnit, unit)
+	}
+
+	res, err := converter.Convert(amount, unit, servingUnit, foodDensity(food))
+	switch {
+	case errors.Is(err, converter.ErrUnknownUnit):
+		return 0, fmt.Errorf("%w: %s", domain.ErrInvalidUnit, unit)
+	case errors.Is(err, converter.ErrIncompatibleUnit):
+		return 0, domain.ErrDensityRequired
+	case err != nil:
+		return 0, err
+	}
+
+	return res, nil
+}
+
+// normalizePortions merapikan nama portion dan menolak nama ganda (tanpa membedakan huruf besar)
+func normalizePortions(portions []domain.FoodPortion) ([]domain.FoodPortion, error) {
+	res := make([]domain.FoodPortion, 0, len(portions))
+	seen := make(map[string]bool, len(portions))
+
+	for _, p := range portions {
+		p.Name = strings.TrimSpace(p.Name)
+
+		key := strings.ToLower(p.Name)
+		if seen[key] {
+			return nil, fmt.Errorf("%w: %s", domain.ErrDuplicatePortion, p.Name)
+		}
+		seen[key] = true
+
+		res = append(res, p)
+	}
+
+	return res, nil
+}
+
+func (s *FoodService) AddPortion(ctx context.Context, viewer domain.FoodViewer, foodID int64, portion domain.FoodPortion) (*domain.FoodPortion, error) {
+	portion.Name = strings.TrimSpace(portion.Name)
+	if err := s.validator.Struct(portion); err != nil {
+		return nil, err
+	}
+
+	food, err := getModifiableFood(ctx, s.store, viewer, foodID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := portionsSupported(food); err != nil {
+		return nil, err
+	}
+
+	before := *food
+
+	if err := s.store.Foods.CreatePortion(ctx, food.ID, &portion); err != nil {
+		if helper.IsDuplicateKeyError(err) {
+			return nil, domain.ErrDuplicatePortion
+		}
+		return nil, err
+	}
+
+	food.Portions = append(append([]domain.FoodPortion{}, food.Portions...), portion)
+	recordAudit(ctx, s.store, domain.AuditFoodUpdate, domain.AuditTargetFood, food.ID, auditDiff(before, food))
+
+	return &portion, nil
+}
+
+func (s *FoodService) DeletePortion(ctx context.Context, viewer domain.FoodViewer, foodID, portionID int64) error {
+	food, err := getModifiableFood(ctx, s.store, viewer, foodID)
+	if err != nil {
+		return err
+	}
+
+	before := *food
+
+	if err := s.store.Foods.DeletePortion(ctx, food.ID, portionID); err != nil {
+		return err
+	}
+
+	food.Portions = make([]domain.FoodPortion, 0, len(before.Portions))
+	for _, p := range before.Portions {
+		if p.ID != portionID {
+			food.Portions = append(food.Portions, p)
+		}
+	}
+	recordAudit(ctx, s.store, domain.AuditFoodUpdate, domain.AuditTargetFood, food.ID, auditDiff(before, food))
+
+	return nil
+}
+
+func (s *FoodService) Delete(ctx context.Context, viewer domain.FoodViewer, id int64) error {
+	food, err := getModifiableFood(ctx, s.store, viewer, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.Foods.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditFoodDelete, domain.AuditTargetFood, id, auditDiff(food, struct{}{}))
+
+	return nil
+}
+
+// Submit mengajukan food private ke katalog publik, menunggu review editor
+func (s *FoodService) Submit(ctx context.Context, viewer domain.FoodViewer, id int64) (*domain.Food, error) {
+	food, err := getModifiableFood(ctx, s.store, viewer, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if food.Visibility != domain.FoodVisibilityPrivate {
+		return nil, domain.ErrFoodNotPrivate
+	}
+
+	if err := requirePublicIngredients(ctx, s.store, food.ID); err != nil {
+		return nil, err
+	}
+
+	return s.changeVisibility(ctx, food, domain.FoodVisibilitySubmitted, domain.AuditFoodSubmit)
+}
+
+// Publish menyetujui pengajuan sehingga food masuk katalog publik
+func (s *FoodService) Publish(ctx context.Context, id int64) (*domain.Food, error) {
+	return s.review(ctx, id, domain.FoodVisibilityPublic, domain.AuditFoodPublish)
+}
+
+// Reject menolak pengajuan, food kembali private milik pengaju
+func (s *FoodService) Reject(ctx context.Context, id int64) (*domain.Food, error) {
+	return s.review(ctx, id, domain.FoodVisibilityPrivate, domain.AuditFoodReject)
+}
+
+func (s *FoodService) review(ctx context.Context, id int64, visibility, action string) (*domain.Food, error) {
+	food, err := s.store.Foods.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if food.Visibility != domain.FoodVisibilitySubmitted {
+		return nil, domain.ErrFoodNotSubmitted
+	}
+
+	// Bahan resep bisa saja masih private saat diajukan atau berubah sejak itu
+	if visibility == domain.FoodVisibilityPublic {
+		if err := requirePublicIngredients(ctx, s.store, food.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.changeVisibility(ctx, food, visibility, action)
+}
+
+func (s *FoodService) changeVisibility(ctx context.Context, food *domain.Food, visibility, action string) (*domain.Food, error) {
+	before := *food
+
+	// Visibility dicek ulang di store supaya dua review bersamaan tidak saling menimpa
+	if err := s.store.Foods.SetVisibility(ctx, food.ID, food.Visibility, visibility); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, domain.ErrConflict
+		}
+		return nil, err
+	}
+	food.Visibility = visibility
+
+	recordAudit(ctx, s.store, action, domain.AuditTargetFood, food.ID, auditDiff(before, food))
+
+	localizeFood(ctx, food)
+
+	return food, nil
 }
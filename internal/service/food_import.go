@@ -0,0 +1,798 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/store"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	importBatchSize   = 200
+	importJobs        = 1
+	importLease       = 10 * time.Minute
+	importMaxAttempts = 3
+	importMaxLine     = 1 << 20
+
+	importNutrientPrefix = "nutrients."
+)
+
+// importFields adalah field food yang bisa diisi dari file import, nutrient memakai "nutrients.<slug>"
+var importFields = []string{"external_id", "name", "description", "serving_size", "serving_unit", "density"}
+
+type FoodImportService struct {
+	store     store.Storage
+	validator validator.Validate
+	storage   domain.FileStorage
+}
+
+// Request memeriksa mapping (dan header CSV), menyimpan file lalu mengantrekan import untuk
+// diproses worker di background
+func (s *FoodImportService) Request(ctx context.Context, input *domain.FoodImportInput) (*domain.FoodImport, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, err
+	}
+
+	if input.Mapping == nil {
+		input.Mapping = map[string]string{}
+	}
+
+	nutrients, err := s.store.Foods.ListNutrients(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := newImportColumns(input.Mapping, nutrients)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := "application/x-ndjson"
+	if input.Format == domain.FoodImportCSV {
+		contentType = "text/csv"
+
+		header, err := csv.NewReader(input.File).Read()
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot read CSV header", domain.ErrInvalidImport)
+		}
+
+		if err := columns.checkHeader(csvHeader(header)); err != nil {
+			return nil, err
+		}
+
+		if _, err := input.File.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	objectName := fmt.Sprintf("imports/foods/%d.%s", time.Now().UnixNano(), input.Format)
+	objectKey, err := s.storage.Upload(ctx, objectName, input.File, input.Size, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &domain.FoodImport{
+		Format:    input.Format,
+		DryRun:    input.DryRun,
+		Mapping:   input.Mapping,
+		ObjectKey: &objectKey,
+	}
+
+	if actor, ok := domain.ActorFrom(ctx); ok {
+		imp.CreatedBy = &actor.UserID
+	}
+
+	if err := s.store.FoodImports.Create(ctx, imp); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditFoodImport, domain.AuditTargetImport, imp.ID, nil)
+
+	return imp, nil
+}
+
+func (s *FoodImportService) Get(ctx context.Context, id int64) (*domain.FoodImport, error) {
+	return s.store.FoodImports.GetByID(ctx, id)
+}
+
+// ListErrors mengembalikan laporan baris yang gagal, urut berdasarkan nomor baris
+func (s *FoodImportService) ListErrors(ctx context.Context, filter domain.FoodImportErrorFilter) ([]*domain.FoodImportError, error) {
+	if err := s.validator.Struct(filter); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.FoodImports.GetByID(ctx, filter.ImportID); err != nil {
+		return nil, err
+	}
+
+	return s.store.FoodImports.ListErrors(ctx, filter)
+}
+
+func (s *FoodImportService) ProcessPending(ctx context.Context) error {
+	imports, err := s.store.FoodImports.Claim(ctx, importJobs, importLease)
+	if err != nil {
+		return err
+	}
+
+	for _, imp := range imports {
+		if err := s.process(ctx, imp); err != nil {
+			log.Printf("Failed to import foods %d (attempt %d): %v", imp.ID, imp.Attempts, err)
+
+			retry := imp.Attempts < importMaxAttempts
+			if err := s.store.FoodImports.MarkFailed(ctx, imp.ID, err, retry); err != nil {
+				return err
+			}
+
+			if !retry && imp.ObjectKey != nil {
+				if err := s.storage.Delete(ctx, *imp.ObjectKey); err != nil {
+					log.Printf("Failed to delete food import file %s: %v", *imp.ObjectKey, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// process membaca file dari awal tapi melewati baris yang sudah diproses, jadi import yang
+// diambil ulang setelah instance mati dilanjutkan dari batch terakhir
+func (s *FoodImportService) process(ctx context.Context, imp *domain.FoodImport) error {
+	if imp.ObjectKey == nil {
+		return errors.New("food import file is missing")
+	}
+
+	// Revisi food yang dibuat import dicatat atas nama admin yang mengunggah file
+	if imp.CreatedBy != nil {
+		ctx = domain.WithActor(ctx, domain.Actor{UserID: *imp.CreatedBy})
+	}
+
+	nutrients, err := s.store.Foods.ListNutrients(ctx)
+	if err != nil {
+		return err
+	}
+
+	columns, err := newImportColumns(imp.Mapping, nutrients)
+	if err != nil {
+		return err
+	}
+
+	if imp.TotalRows == nil {
+		total, err := s.countRows(ctx, imp, columns)
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.FoodImports.SetTotalRows(ctx, imp.ID, total); err != nil {
+			return err
+		}
+	}
+
+	obj, err := s.storage.Download(ctx, *imp.ObjectKey)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+
+	reader, err := newImportReader(imp.Format, obj, columns)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]int{}
+	batch := make([]*importRow, 0, importBatchSize)
+
+	for {
+		row, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if row.row <= imp.ProcessedRows {
+			continue
+		}
+
+		batch = append(batch, row)
+		if len(batch) == importBatchSize {
+			if err := s.saveBatch(ctx, imp, batch, seen); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := s.saveBatch(ctx, imp, batch, seen); err != nil {
+			return err
+		}
+	}
+
+	if err := s.storage.Delete(ctx, *imp.ObjectKey); err != nil {
+		return err
+	}
+
+	return s.store.FoodImports.MarkCompleted(ctx, imp.ID)
+}
+
+func (s *FoodImportService) countRows(ctx context.Context, imp *domain.FoodImport, columns *importColumns) (int, error) {
+	obj, err := s.storage.Download(ctx, *imp.ObjectKey)
+	if err != nil {
+		return 0, err
+	}
+	defer obj.Close()
+
+	reader, err := newImportReader(imp.Format, obj, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for {
+		if _, err := reader.next(); errors.Is(err, io.EOF) {
+			return total, nil
+		} else if err != nil {
+			return 0, err
+		}
+		total++
+	}
+}
+
+// saveBatch mencocokkan baris dengan food yang sudah ada lalu menyimpannya. seen berisi key
+// baris yang sudah diproses supaya baris ganda di file tidak membuat food dua kali.
+func (s *FoodImportService) saveBatch(ctx context.Context, imp *domain.FoodImport, batch []*importRow, seen map[string]int) error {
+	var externalIDs, names []string
+	for _, row := range batch {
+		if row.record == nil {
+			continue
+		}
+		if row.record.externalID != "" {
+			externalIDs = append(externalIDs, row.record.externalID)
+		}
+		if row.record.name != "" {
+			names = append(names, strings.ToLower(row.record.name))
+		}
+	}
+
+	byExternalID, byName, err := s.store.Foods.FindForImport(ctx, externalIDs, names)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]*domain.FoodImportRow, len(batch))
+	befores := make([]*domain.Food, len(batch))
+	targets := map[int64]int{}
+
+	for i, row := range batch {
+		res := &domain.FoodImportRow{Row: row.row, Error: row.err}
+		rows[i] = res
+
+		if row.record == nil {
+			continue
+		}
+		res.Key = row.record.key()
+
+		seenKey := row.record.seenKey()
+		if prev, ok := seen[seenKey]; ok {
+			res.Error = fmt.Sprintf("duplicate of row %d", prev)
+			continue
+		}
+
+		food, before, err := row.record.food(byExternalID, byName)
+		if err != nil {
+			res.Error = err.Error()
+			continue
+		}
+
+		// Dua baris dengan external_id berbeda bisa cocok dengan nama food yang sama
+		if prev, ok := targets[food.ID]; ok && food.ID != 0 {
+			res.Error = fmt.Sprintf("matches the same food as row %d", prev)
+			continue
+		}
+		targets[food.ID] = row.row
+
+		res.Food = food
+		seen[seenKey] = row.row
+		befores[i] = before
+	}
+
+	if err := s.store.FoodImports.SaveBatch(ctx, imp, rows, importLease); err != nil {
+		return err
+	}
+
+	if imp.DryRun {
+		return nil
+	}
+
+	// Tiap food yang tersimpan dicatat di audit seperti perubahan lewat API, resep yang
+	// memakai food yang diperbarui dihitung ulang
+	for i, row := range rows {
+		if row.Error != "" || row.Food == nil {
+			continue
+		}
+
+		if befores[i] == nil {
+			recordAudit(ctx, s.store, domain.AuditFoodCreate, domain.AuditTargetFood, row.Food.ID, auditDiff(struct{}{}, row.Food))
+			continue
+		}
+
+		recordAudit(ctx, s.store, domain.AuditFoodUpdate, domain.AuditTargetFood, row.Food.ID, auditDiff(befores[i], row.Food))
+		refreshRecipes(ctx, s.store, row.Food.ID)
+	}
+
+	return nil
+}
+
+// importColumn adalah tujuan satu kolom file: field food atau nutrient
+type importColumn struct {
+	field    string
+	nutrient *domain.Nutrient
+}
+
+func (c *importColumn) target() string {
+	if c.nutrient != nil {
+		return importNutrientPrefix + c.nutrient.Slug
+	}
+	return c.field
+}
+
+// importColumns memetakan kolom file ke field food. Mapping kosong berarti kolom sengaja
+// diabaikan, kolom tanpa mapping dicocokkan dengan nama field atau nama/slug nutrient.
+type importColumns struct {
+	mapping  map[string]string
+	bySlug   map[string]*domain.Nutrient
+	byName   map[string]*domain.Nutrient
+	resolved map[string]*importColumn
+}
+
+func newImportColumns(mapping map[string]string, nutrients []*domain.Nutrient) (*importColumns, error) {
+	c := &importColumns{
+		mapping:  mapping,
+		bySlug:   make(map[string]*domain.Nutrient, len(nutrients)),
+		byName:   make(map[string]*domain.Nutrient, len(nutrients)*2),
+		resolved: map[string]*importColumn{},
+	}
+
+	for _, n := range nutrients {
+		c.bySlug[n.Slug] = n
+		c.byName[strings.ToLower(n.Name)] = n
+		c.byName[n.Slug] = n
+	}
+
+	for column, target := range mapping {
+		if target == "" {
+			continue
+		}
+
+		if _, ok := c.parseTarget(target); !ok {
+			return nil, fmt.Errorf("%w: unknown target %q for column %q", domain.ErrInvalidImport, target, column)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *importColumns) parseTarget(target string) (*importColumn, bool) {
+	if slug, ok := strings.CutPrefix(target, importNutrientPrefix); ok {
+		n, ok := c.bySlug[slug]
+		return &importColumn{nutrient: n}, ok
+	}
+
+	return &importColumn{field: target}, slices.Contains(importFields, target)
+}
+
+// resolve mengembalikan nil untuk kolom yang diabaikan
+func (c *importColumns) resolve(column string) *importColumn {
+	if col, ok := c.resolved[column]; ok {
+		return col
+	}
+
+	var col *importColumn
+	if target, ok := c.mapping[column]; ok {
+		if target != "" {
+			col, _ = c.parseTarget(target)
+		}
+	} else {
+		key := strings.ToLower(strings.TrimSpace(column))
+		if slices.Contains(importFields, key) {
+			col = &importColumn{field: key}
+		} else if n, ok := c.byName[key]; ok {
+			col = &importColumn{nutrient: n}
+		}
+	}
+
+	c.resolved[column] = col
+	return col
+}
+
+// checkHeader menolak header CSV yang tidak punya kolom name atau external_id, atau yang
+// beberapa kolomnya dipetakan ke field yang sama
+func (c *importColumns) checkHeader(header []string) error {
+	targets := map[string]string{}
+
+	for _, column := range header {
+		col := c.resolve(column)
+		if col == nil {
+			continue
+		}
+
+		target := col.target()
+		if prev, ok := targets[target]; ok {
+			return fmt.Errorf("%w: columns %q and %q both map to %s", domain.ErrInvalidImport, prev, column, target)
+		}
+		targets[target] = column
+	}
+
+	if targets["name"] == "" && targets["external_id"] == "" {
+		return fmt.Errorf("%w: file needs a name or external_id column", domain.ErrInvalidImport)
+	}
+
+	return nil
+}
+
+type importValue struct {
+	column string
+	value  string
+}
+
+// importRecord adalah isi satu baris file, nilai kosong berarti kolom tidak diisi. Jumlah
+// nutrient dalam satuan nutrient per serving food.
+type importRecord struct {
+	externalID  string
+	name        string
+	description *string
+	servingSize *float64
+	servingUnit *string
+	density     *float64
+	nutrients   []domain.NutrientAmount
+}
+
+func (c *importColumns) record(values []importValue) (*importRecord, error) {
+	rec := &importRecord{}
+	targets := map[string]string{}
+
+	for _, v := range values {
+		col := c.resolve(v.column)
+		value := strings.TrimSpace(v.value)
+		if col == nil || value == "" {
+			continue
+		}
+
+		target := col.target()
+		if prev, ok := targets[target]; ok {
+			return nil, fmt.Errorf("columns %q and %q both map to %s", prev, v.column, target)
+		}
+		targets[target] = v.column
+
+		switch col.field {
+		case "external_id":
+			if len(value) > 100 {
+				return nil, fmt.Errorf("external_id is longer than 100 characters")
+			}
+			rec.externalID = value
+		case "name":
+			rec.name = value
+		case "description":
+			rec.description = &value
+		case "serving_unit":
+			rec.servingUnit = &value
+		case "serving_size", "density":
+			f, err := parseImportNumber(v.column, value)
+			if err != nil {
+				return nil, err
+			}
+			if f <= 0 {
+				return nil, fmt.Errorf("%s must be greater than 0", col.field)
+			}
+
+			if col.field == "density" {
+				rec.density = &f
+			} else {
+				rec.servingSize = &f
+			}
+		default:
+			f, err := parseImportNumber(v.column, value)
+			if err != nil {
+				return nil, err
+			}
+
+			rec.nutrients = append(rec.nutrients, domain.NutrientAmount{
+				ID:     col.nutrient.ID,
+				Name:   col.nutrient.Name,
+				Slug:   col.nutrient.Slug,
+				Unit:   col.nutrient.Unit,
+				Amount: f,
+			})
+		}
+	}
+
+	if rec.name == "" && rec.externalID == "" {
+		return nil, errors.New("name or external_id is required")
+	}
+
+	return rec, nil
+}
+
+func parseImportNumber(column, value string) (float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("invalid number %q in column %q", value, column)
+	}
+	return f, nil
+}
+
+func (r *importRecord) key() string {
+	if r.externalID != "" {
+		return r.externalID
+	}
+	return r.name
+}
+
+func (r *importRecord) seenKey() string {
+	if r.externalID != "" {
+		return "external_id:" + r.externalID
+	}
+	return "name:" + strings.ToLower(r.name)
+}
+
+// food mencocokkan baris dengan food yang sudah ada, berdasarkan external_id lalu nama.
+// Food katalog dengan nama sama tapi external_id lain dianggap food yang berbeda. Baris yang
+// tidak cocok menjadi food publik baru, nutrient yang tidak ada di baris dibiarkan. before
+// berisi food lama (nil untuk food baru) untuk audit.
+func (r *importRecord) food(byExternalID map[string]*domain.Food, byName map[string][]*domain.Food) (food, before *domain.Food, err error) {
+	existing := byExternalID[r.externalID]
+
+	if existing == nil && r.name != "" {
+		var candidates []*domain.Food
+		for _, f := range byName[strings.ToLower(r.name)] {
+			if r.externalID == "" || f.ExternalID == nil {
+				candidates = append(candidates, f)
+			}
+		}
+
+		if len(candidates) > 1 {
+			return nil, nil, fmt.Errorf("name matches %d catalog foods, add an external_id", len(candidates))
+		}
+		if len(candidates) == 1 {
+			existing = candidates[0]
+		}
+	}
+
+	food = &domain.Food{Visibility: domain.FoodVisibilityPublic, Nutrients: []domain.NutrientAmount{}}
+	if existing != nil {
+		if foodServingUnit(existing) == domain.ServingUnitServing {
+			return nil, nil, domain.ErrRecipeComputed
+		}
+
+		copied := *existing
+		food = &copied
+		food.Nutrients = slices.Clone(existing.Nutrients)
+
+		// Barcode dan nama lain tidak diubah import
+		food.Barcodes = nil
+		food.Names = nil
+
+		snapshot := *food
+		snapshot.Nutrients = existing.Nutrients
+		before = &snapshot
+	} else if r.name == "" {
+		return nil, nil, errors.New("name is required for a new food")
+	}
+
+	// Food kanonik hasil merge tetap memakai external_id miliknya sendiri
+	if r.externalID != "" && food.ExternalID == nil {
+		food.ExternalID = &r.externalID
+	}
+	if r.name != "" {
+		food.Name = r.name
+	}
+	if r.description != nil {
+		food.Description = *r.description
+	}
+	if r.servingSize != nil {
+		food.ServingSize = r.servingSize
+	}
+	if r.density != nil {
+		food.Density = r.density
+	}
+
+	if food.ServingSize == nil {
+		servingSize := float64(100)
+		food.ServingSize = &servingSize
+	}
+
+	servingUnit := foodServingUnit(food)
+	if r.servingUnit != nil {
+		servingUnit = *r.servingUnit
+	}
+
+	unit, err := normalizeServingUnit(servingUnit)
+	if err != nil {
+		return nil, nil, err
+	}
+	food.ServingUnit = &unit
+
+	if len(food.Portions) > 0 && (r.servingUnit != nil || r.density != nil) {
+		if err := portionsSupported(food); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, n := range r.nutrients {
+		i := slices.IndexFunc(food.Nutrients, func(e domain.NutrientAmount) bool { return e.ID == n.ID })
+		if i >= 0 {
+			food.Nutrients[i] = n
+		} else {
+			food.Nutrients = append(food.Nutrients, n)
+		}
+	}
+
+	if err := validateFoodNutrients(*food); err != nil {
+		return nil, nil, err
+	}
+
+	return food, before, nil
+}
+
+// importRow adalah satu baris file. Nomor baris dimulai dari 1 tanpa header CSV dan baris
+// kosong NDJSON. Baris yang tidak bisa dibaca punya err dan record nil.
+type importRow struct {
+	row    int
+	record *importRecord
+	err    string
+}
+
+// importReader membaca file import baris demi baris. Hanya error file (bukan error baris)
+// yang menghentikan import.
+type importReader interface {
+	next() (*importRow, error)
+}
+
+func newImportReader(format string, r io.Reader, columns *importColumns) (importReader, error) {
+	if format == domain.FoodImportNDJSON {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), importMaxLine)
+		return &ndjsonImportReader{scanner: scanner, columns: columns}, nil
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read CSV header", domain.ErrInvalidImport)
+	}
+
+	return &csvImportReader{reader: reader, header: csvHeader(header), columns: columns}, nil
+}
+
+// csvHeader membuang BOM UTF-8 dari file yang disimpan Excel
+func csvHeader(header []string) []string {
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	return header
+}
+
+type csvImportReader struct {
+	reader  *csv.Reader
+	header  []string
+	columns *importColumns
+	row     int
+}
+
+func (r *csvImportReader) next() (*importRow, error) {
+	record, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	r.row++
+	row := &importRow{row: r.row}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		row.err = parseErr.Err.Error()
+		return row, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]importValue, 0, len(record))
+	for i, value := range record {
+		if i < len(r.header) {
+			values = append(values, importValue{column: r.header[i], value: value})
+		}
+	}
+
+	if row.record, err = r.columns.record(values); err != nil {
+		row.err = err.Error()
+	}
+
+	return row, nil
+}
+
+type ndjsonImportReader struct {
+	scanner *bufio.Scanner
+	columns *importColumns
+	row     int
+}
+
+func (r *ndjsonImportReader) next() (*importRow, error) {
+	var line string
+	for line == "" {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return nil, fmt.Errorf("%w: %v", domain.ErrInvalidImport, err)
+			}
+			return nil, io.EOF
+		}
+		line = strings.TrimSpace(r.scanner.Text())
+	}
+
+	r.row++
+	row := &importRow{row: r.row}
+
+	values, err := ndjsonValues(line)
+	if err != nil {
+		row.err = err.Error()
+		return row, nil
+	}
+
+	if row.record, err = r.columns.record(values); err != nil {
+		row.err = err.Error()
+	}
+
+	return row, nil
+}
+
+// ndjsonValues mengubah satu object JSON datar menjadi nilai kolom, diurutkan berdasarkan key
+// supaya pesan error konsisten
+func ndjsonValues(line string) ([]importValue, error) {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := make([]importValue, 0, len(obj))
+	for _, key := range keys {
+		var value string
+		switch v := obj[key].(type) {
+		case nil:
+		case string:
+			value = v
+		case json.Number:
+			value = v.String()
+		default:
+			return nil, fmt.Errorf("unsupported value for %q, expected a string or number", key)
+		}
+		values = append(values, importValue{column: key, value: value})
+	}
+
+	return values, nil
+}
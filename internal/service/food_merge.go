@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/store"
+	"github.com/MyFirstGo/pkg/converter"
+)
+
+// FindDuplicates mencari food katalog dengan nama mirip lalu menyaring berdasarkan jarak
+// nutrient per 100g. Pasangan yang jaraknya tidak bisa dihitung tetap ditampilkan.
+func (s *FoodService) FindDuplicates(ctx context.Context, filter domain.FoodDuplicateFilter) ([]*domain.FoodDuplicate, error) {
+	if err := s.validator.Struct(filter); err != nil {
+		return nil, err
+	}
+
+	// Ambil kandidat lebih banyak karena sebagian akan tersaring oleh jarak nutrient
+	candidates, err := s.store.Foods.FindDuplicates(ctx, filter.MinSimilarity, min(filter.Limit*5, 500))
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates := make([]*domain.FoodDuplicate, 0, filter.Limit)
+	for _, d := range candidates {
+		d.NutrientDistance = nutrientDistance(d.Food, d.Duplicate)
+		if d.NutrientDistance != nil && *d.NutrientDistance > filter.MaxDistance {
+			continue
+		}
+
+		localizeFood(ctx, d.Food)
+		localizeFood(ctx, d.Duplicate)
+		duplicates = append(duplicates, d)
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicateScore(duplicates[i]) > duplicateScore(duplicates[j])
+	})
+
+	if len(duplicates) > filter.Limit {
+		duplicates = duplicates[:filter.Limit]
+	}
+
+	return duplicates, nil
+}
+
+// duplicateScore: kemiripan nama dikurangi jarak nutrient, jarak yang tidak diketahui dianggap 0.5
+func duplicateScore(d *domain.FoodDuplicate) float64 {
+	distance := 0.5
+	if d.NutrientDistance != nil {
+		distance = *d.NutrientDistance
+	}
+
+	return d.NameSimilarity * (1 - distance)
+}
+
+// nutrientDistance adalah rata-rata selisih relatif nutrient per 100g kedua food (0 sampai 1).
+// Nutrient yang hanya ada di salah satu food dihitung sebagai selisih penuh.
+func nutrientDistance(a, b *domain.Food) *float64 {
+	va, ok := nutrientsPer100g(a)
+	if !ok {
+		return nil
+	}
+
+	vb, ok := nutrientsPer100g(b)
+	if !ok {
+		return nil
+	}
+
+	ids := map[int64]bool{}
+	for id := range va {
+		ids[id] = true
+	}
+	for id := range vb {
+		ids[id] = true
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var total float64
+	for id := range ids {
+		x, y := va[id], vb[id]
+		if m := math.Max(math.Abs(x), math.Abs(y)); m > 0 {
+			total += math.Abs(x-y) / m
+		}
+	}
+
+	distance := math.Round(total/float64(len(ids))*10000) / 10000
+	return &distance
+}
+
+func nutrientsPer100g(food *domain.Food) (map[int64]float64, bool) {
+	size := 100.0
+	if food.ServingSize != nil {
+		size = *food.ServingSize
+	}
+
+	grams, err := converter.ToGrams(size, foodServingUnit(food), foodDensity(food))
+	if err != nil || grams <= 0 {
+		return nil, false
+	}
+
+	values := make(map[int64]float64, len(food.Nutrients))
+	for _, n := range food.Nutrients {
+		values[n.ID] = n.Amount * 100 / grams
+	}
+
+	return values, true
+}
+
+// Merge menggabungkan food duplikat ke food kanonik. Hanya food katalog (public atau
+// submitted) yang bukan resep yang bisa digabung.
+func (s *FoodService) Merge(ctx context.Context, input *domain.FoodMergeInput) (*domain.FoodMergeResult, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(input.DuplicateIDs))
+	seen := map[int64]bool{}
+	for _, id := range input.DuplicateIDs {
+		if id == input.CanonicalID {
+			return nil, domain.ErrInvalidMerge
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range append([]int64{input.CanonicalID}, ids...) {
+		food, err := s.store.Foods.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if food.Visibility == domain.FoodVisibilityPrivate {
+			return nil, domain.ErrInvalidMerge
+		}
+
+		if _, err := s.store.Recipes.GetByFoodID(ctx, id); err == nil {
+			return nil, domain.ErrInvalidMerge
+		} else if !errors.Is(err, store.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	res, err := s.store.Foods.Merge(ctx, input.CanonicalID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		recordAudit(ctx, s.store, domain.AuditFoodMerge, domain.AuditTargetFood, id, map[string]domain.AuditChange{
+			"merged_into": {From: nil, To: input.CanonicalID},
+		})
+	}
+
+	refreshRecipes(ctx, s.store, input.CanonicalID)
+
+	food, err := s.store.Foods.GetByID(ctx, input.CanonicalID)
+	if err != nil {
+		return nil, err
+	}
+	localizeFood(ctx, food)
+	res.Food = food
+
+	return res, nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/store"
+)
+
+func (s *FoodService) ListRevisions(ctx context.Context, viewer domain.FoodViewer, foodID int64) ([]*domain.FoodRevision, error) {
+	if _, err := getVisibleFood(ctx, s.store, viewer, foodID); err != nil {
+		return nil, err
+	}
+
+	return s.store.Foods.ListRevisions(ctx, foodID)
+}
+
+func (s *FoodService) GetRevision(ctx context.Context, viewer domain.FoodViewer, foodID int64, revision int) (*domain.FoodRevision, error) {
+	if _, err := getVisibleFood(ctx, s.store, viewer, foodID); err != nil {
+		return nil, err
+	}
+
+	return s.store.Foods.GetRevision(ctx, foodID, revision)
+}
+
+// DiffRevisions membandingkan dua revisi food dengan format perubahan yang sama seperti audit log
+func (s *FoodService) DiffRevisions(ctx context.Context, viewer domain.FoodViewer, foodID int64, from, to int) (*domain.FoodRevisionDiff, error) {
+	if _, err := getVisibleFood(ctx, s.store, viewer, foodID); err != nil {
+		return nil, err
+	}
+
+	before, err := s.store.Foods.GetRevision(ctx, foodID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := s.store.Foods.GetRevision(ctx, foodID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.FoodRevisionDiff{
+		FoodID:  foodID,
+		From:    from,
+		To:      to,
+		Changes: auditDiff(revisionFields(before), revisionFields(after)),
+	}, nil
+}
+
+// revisionFields meratakan revisi supaya nutrient dibandingkan satu per satu
+func revisionFields(rev *domain.FoodRevision) map[string]any {
+	fields := map[string]any{
+		"name":         rev.Name,
+		"description":  rev.Description,
+		"serving_size": rev.ServingSize,
+		"serving_unit": rev.ServingUnit,
+		"density":      rev.Density,
+	}
+
+	for _, n := range rev.Nutrients {
+		fields["nutrients."+n.Slug] = n.Amount
+	}
+
+	return fields
+}
+
+// Rollback mengembalikan nilai gizi food ke revisi lama. Hasilnya disimpan sebagai revisi
+// baru, jadi riwayat dan diary yang memakai revisi di antaranya tidak berubah.
+func (s *FoodService) Rollback(ctx context.Context, viewer domain.FoodViewer, foodID int64, revision int) (*domain.Food, error) {
+	food, err := getModifiableFood(ctx, s.store, viewer, foodID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.Recipes.GetByFoodID(ctx, foodID); err == nil {
+		return nil, domain.ErrRecipeComputed
+	} else if !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+
+	rev, err := s.store.Foods.GetRevision(ctx, foodID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *food
+
+	food.Name = rev.Name
+	food.Description = rev.Description
+	food.ServingSize = rev.ServingSize
+	food.ServingUnit = rev.ServingUnit
+	food.Density = rev.Density
+	food.Nutrients = rev.Nutrients
+
+	if len(food.Portions) > 0 {
+		if err := portionsSupported(food); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.store.Foods.Update(ctx, food); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditFoodRollback, domain.AuditTargetFood, food.ID, auditDiff(before, food))
+
+	refreshRecipes(ctx, s.store, food.ID)
+
+	localizeFood(ctx, food)
+
+	return food, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+func accountAttemptKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// lockoutDuration menghitung lama lockout secara eksponensial: base, 2x base, 4x base, ... sampai max
+func lockoutDuration(failures, threshold int, base, max time.Duration) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+
+	d := base
+	for i := threshold; i < failures && d < max; i++ {
+		d *= 2
+	}
+
+	return min(d, max)
+}
+
+// checkLockout dipanggil sebelum password dicek, sehingga percobaan selama lockout tidak dihitung
+func (s *AuthService) checkLockout(ctx context.Context, email, ip string) error {
+	if ip != "" {
+		until, err := s.store.LoginAttempts.LockedUntil(ctx, ipAttemptKey(ip))
+		if err != nil {
+			return err
+		}
+		if until != nil {
+			return &domain.LockoutError{Err: domain.ErrTooManyAttempts, RetryAfter: time.Until(*until)}
+		}
+	}
+
+	until, err := s.store.LoginAttempts.LockedUntil(ctx, accountAttemptKey(email))
+	if err != nil {
+		return err
+	}
+	if until != nil {
+		return &domain.LockoutError{Err: domain.ErrAccountLocked, RetryAfter: time.Until(*until)}
+	}
+
+	return nil
+}
+
+func (s *AuthService) recordLoginFailure(ctx context.Context, email, ip string) error {
+	if err := s.recordFailure(ctx, accountAttemptKey(email), s.config.LoginMaxFailures); err != nil {
+		return err
+	}
+
+	if ip == "" {
+		return nil
+	}
+
+	return s.recordFailure(ctx, ipAttemptKey(ip), s.config.LoginIPMaxFailures)
+}
+
+func (s *AuthService) recordFailure(ctx context.Context, key string, threshold int) error {
+	failures, err := s.store.LoginAttempts.RecordFailure(ctx, key, s.config.LoginFailureWindow)
+	if err != nil {
+		return err
+	}
+
+	d := lockoutDuration(failures, threshold, s.config.LoginLockoutBase, s.config.LoginLockoutMax)
+	if d == 0 {
+		return nil
+	}
+
+	log.Printf("Locking %s for %s after %d failed login attempts", key, d, failures)
+
+	return s.store.LoginAttempts.Lock(ctx, key, time.Now().Add(d))
+}
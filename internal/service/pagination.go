@@ -0,0 +1,17 @@
+package service
+
+import "github.com/MyFirstGo/internal/domain"
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+// normalizePage memakai limit default jika limit di luar 1..maxPageLimit
+func normalizePage(p domain.PageRequest) domain.PageRequest {
+	if p.Limit < 1 || p.Limit > maxPageLimit {
+		p.Limit = defaultPageLimit
+	}
+
+	return p
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/store"
+	"github.com/MyFirstGo/pkg/converter"
+	"github.com/go-playground/validator/v10"
+)
+
+type RecipeService struct {
+	store     store.Storage
+	validator validator.Validate
+}
+
+func (s *RecipeService) Get(ctx context.Context, viewer domain.FoodViewer, foodID int64) (*domain.Recipe, error) {
+	food, err := getVisibleFood(ctx, s.store, viewer, foodID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := s.store.Recipes.GetByFoodID(ctx, foodID)
+	if err != nil {
+		return nil, err
+	}
+	recipe.Food = food
+
+	localizeFood(ctx, food)
+
+	return recipe, nil
+}
+
+func (s *RecipeService) Create(ctx context.Context, viewer domain.FoodViewer, input *domain.RecipeInput) (*domain.Recipe, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, err
+	}
+
+	// Resep default private, resep publik hanya untuk editor katalog seperti food biasa
+	visibility := input.Visibility
+	if visibility == "" {
+		visibility = domain.FoodVisibilityPrivate
+	}
+
+	if visibility == domain.FoodVisibilityPublic && !viewer.Editor {
+		return nil, domain.ErrFoodForbidden
+	}
+
+	names, err := normalizeNames(input.Names)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe := &domain.Recipe{
+		Food: &domain.Food{
+			Name:        input.Name,
+			Description: input.Description,
+			Names:       names,
+			Barcodes:    []string{},
+			Visibility:  visibility,
+		},
+		YieldServings: input.YieldServings,
+		YieldWeight:   input.YieldWeight,
+	}
+
+	if visibility != domain.FoodVisibilityPublic {
+		recipe.OwnerID = &viewer.UserID
+	}
+
+	if err := s.compose(ctx, viewer, recipe, input.Ingredients); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Recipes.Create(ctx, recipe); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditFoodCreate, domain.AuditTargetFood, recipe.ID, auditDiff(struct{}{}, recipe))
+
+	localizeFood(ctx, recipe.Food)
+
+	return recipe, nil
+}
+
+// Update mengganti isi resep. Visibility tidak berubah, pakai submit/publish seperti food biasa.
+func (s *RecipeService) Update(ctx context.Context, viewer domain.FoodViewer, foodID int64, input *domain.RecipeInput) (*domain.Recipe, error) {
+	if err := s.validator.Struct(input); err != nil {
+		return nil, err
+	}
+
+	food, err := getModifiableFood(ctx, s.store, viewer, foodID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := s.store.Recipes.GetByFoodID(ctx, foodID)
+	if err != nil {
+		return nil, err
+	}
+	recipe.Food = food
+
+	beforeFood := *food
+	before := *recipe
+	before.Food = &beforeFood
+
+	names, err := normalizeNames(input.Names)
+	if err != nil {
+		return nil, err
+	}
+
+	food.Name = input.Name
+	food.Description = input.Description
+	food.Names = names
+	recipe.YieldServings = input.YieldServings
+	recipe.YieldWeight = input.YieldWeight
+
+	if err := s.compose(ctx, viewer, recipe, input.Ingredients); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Recipes.Update(ctx, recipe); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditFoodUpdate, domain.AuditTargetFood, recipe.ID, auditDiff(&before, recipe))
+
+	localizeFood(ctx, food)
+
+	return recipe, nil
+}
+
+// compose memuat bahan, memastikan bahan boleh dipakai viewer, lalu menghitung serving dan nutrient resep
+func (s *RecipeService) compose(ctx context.Context, viewer domain.FoodViewer, recipe *domain.Recipe, inputs []domain.RecipeIngredientInput) error {
+	ingredients := make([]*domain.Food, 0, len(inputs))
+	recipe.Ingredients = make([]domain.RecipeIngredient, 0, len(inputs))
+
+	for _, in := range inputs {
+		food, err := getVisibleFood(ctx, s.store, viewer, in.FoodID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return fmt.Errorf("%w: food %d", domain.ErrInvalidIngredient, in.FoodID)
+			}
+			return err
+		}
+
+		// Resep bersarang tidak didukung supaya perhitungan ulang tidak berantai (dan tidak bisa siklik)
+		if _, err := s.store.Recipes.GetByFoodID(ctx, in.FoodID); err == nil {
+			return fmt.Errorf("%w: food %d", domain.ErrInvalidIngredient, in.FoodID)
+		} else if !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+
+		if recipe.Visibility == domain.FoodVisibilityPublic && food.Visibility != domain.FoodVisibilityPublic {
+			return fmt.Errorf("%w: food %d", domain.ErrPrivateIngredient, in.FoodID)
+		}
+
+		// Quantity disimpan dalam satuan yang dikirim, bukan serving_unit bahan, supaya
+		// resep tidak berubah jika serving_unit bahan diubah
+		if _, err := toServingUnit(food, in.Quantity, in.Unit); err != nil {
+			return fmt.Errorf("%w: food %d", err, in.FoodID)
+		}
+
+		unit := foodServingUnit(food)
+		if in.Unit != "" && in.Unit != unit {
+			u, err := converter.ParseUnit(in.Unit)
+			if err != nil {
+				return fmt.Errorf("%w: %s", domain.ErrInvalidUnit, in.Unit)
+			}
+			unit = u.Symbol
+		}
+
+		ingredients = append(ingredients, food)
+		recipe.Ingredients = append(recipe.Ingredients, domain.RecipeIngredient{
+			FoodID:   food.ID,
+			FoodName: food.Name,
+			Quantity: in.Quantity,
+			Unit:     unit,
+		})
+	}
+
+	return computeRecipe(recipe, ingredients)
+}
+
+// requirePublicIngredients memastikan resep yang diajukan atau dipublikasikan hanya memakai
+// bahan dari katalog publik. Food yang bukan resep selalu lolos.
+func requirePublicIngredients(ctx context.Context, st store.Storage, foodID int64) error {
+	recipe, err := st.Recipes.GetByFoodID(ctx, foodID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	for _, ing := range recipe.Ingredients {
+		food, err := st.Foods.GetByID(ctx, ing.FoodID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return fmt.Errorf("%w: food %d", domain.ErrInvalidIngredient, ing.FoodID)
+			}
+			return err
+		}
+
+		if food.Visibility != domain.FoodVisibilityPublic {
+			return fmt.Errorf("%w: food %d", domain.ErrPrivateIngredient, ing.FoodID)
+		}
+	}
+
+	return nil
+}
+
+// computeRecipe menghitung serving dan nutrient resep. Quantity bahan dikonversi ke
+// serving_unit bahan saat ini lalu nutrient diskalakan quantity / serving_size bahan, sama
+// seperti perhitungan diary. Yield porsi menghasilkan serving 1 porsi, yield berat
+// menghasilkan serving seberat hasil masakan (gram).
+func computeRecipe(recipe *domain.Recipe, ingredients []*domain.Food) error {
+	totals := map[int64]*domain.NutrientAmount{}
+	var order []int64
+
+	for i, food := range ingredients {
+		servingSize := 100.0
+		if food.ServingSize != nil && *food.ServingSize > 0 {
+			servingSize = *food.ServingSize
+		}
+
+		ing := recipe.Ingredients[i]
+		quantity, err := toServingUnit(food, ing.Quantity, ing.Unit)
+		if err != nil {
+			return fmt.Errorf("%w: food %d", err, ing.FoodID)
+		}
+		factor := quantity / servingSize
+
+		for _, n := range food.Nutrients {
+			total, ok := totals[n.ID]
+			if !ok {
+				total = &domain.NutrientAmount{ID: n.ID, Name: n.Name, Slug: n.Slug, Unit: n.Unit}
+				totals[n.ID] = total
+				order = append(order, n.ID)
+			}
+			total.Amount += n.Amount * factor
+		}
+	}
+
+	size, unit, divisor := 1.0, domain.ServingUnitServing, 1.0
+	if recipe.YieldServings != nil {
+		divisor = *recipe.YieldServings
+	} else if recipe.YieldWeight != nil {
+		size, unit = *recipe.YieldWeight, "g"
+	}
+
+	recipe.ServingSize = &size
+	recipe.ServingUnit = &unit
+
+	// Kolom amount numeric(10,2)
+	recipe.Nutrients = make([]domain.NutrientAmount, 0, len(order))
+	for _, id := range order {
+		n := *totals[id]
+		n.Amount = math.Round(n.Amount/divisor*100) / 100
+		recipe.Nutrients = append(recipe.Nutrients, n)
+	}
+
+	return nil
+}
+
+// refreshRecipes menghitung ulang resep yang memakai food sebagai bahan setelah nutrient
+// atau serving food tersebut berubah. Kegagalan hanya di-log karena perubahan food sudah tersimpan.
+func refreshRecipes(ctx context.Context, st store.Storage, foodID int64) {
+	ids, err := st.Recipes.ListIDsByIngredient(ctx, []int64{foodID})
+	if err != nil {
+		log.Printf("Failed to list recipes using food %d: %v", foodID, err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := refreshRecipe(ctx, st, id); err != nil {
+			log.Printf("Failed to refresh recipe %d: %v", id, err)
+		}
+	}
+}
+
+func refreshRecipe(ctx context.Context, st store.Storage, recipeID int64) error {
+	food, err := st.Foods.GetByID(ctx, recipeID)
+	if err != nil {
+		return err
+	}
+
+	recipe, err := st.Recipes.GetByFoodID(ctx, recipeID)
+	if err != nil {
+		return err
+	}
+	recipe.Food = food
+
+	// Bahan yang sudah dihapus membuat resep dibiarkan apa adanya
+	ingredients := make([]*domain.Food, 0, len(recipe.Ingredients))
+	for _, ing := range recipe.Ingredients {
+		ingredient, err := st.Foods.GetByID(ctx, ing.FoodID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+
+	if err := computeRecipe(recipe, ingredients); err != nil {
+		return err
+	}
+
+	return st.Recipes.Update(ctx, recipe)
+}
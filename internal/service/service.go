@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"time"
 
@@ -10,23 +11,131 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
+// VerificationPolicy menentukan aksi apa yang diblokir sebelum email diverifikasi
+type VerificationPolicy string
+
+const (
+	VerifyNone  VerificationPolicy = "none"
+	VerifyLogin VerificationPolicy = "login"
+	VerifyDiary VerificationPolicy = "diary"
+)
+
+// ParseVerificationPolicy menolak nilai yang tidak dikenal supaya salah ketik di
+// konfigurasi tidak diam-diam mematikan verifikasi email
+func ParseVerificationPolicy(s string) (VerificationPolicy, error) {
+	switch p := VerificationPolicy(s); p {
+	case VerifyNone, VerifyLogin, VerifyDiary:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid email verification policy %q, expected none, login or diary", s)
+	}
+}
+
+// Config berisi pengaturan service yang dibaca dari environment di main
+type Config struct {
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+	PasswordResetTTL     time.Duration
+	PasswordResetURL     string
+	EmailVerification    VerificationPolicy
+	EmailVerificationTTL time.Duration
+	EmailVerificationURL string
+
+	// Brute-force protection
+	LoginMaxFailures   int
+	LoginIPMaxFailures int
+	LoginFailureWindow time.Duration
+	LoginLockoutBase   time.Duration
+	LoginLockoutMax    time.Duration
+
+	TOTPIssuer      string
+	MFAChallengeTTL time.Duration
+
+	// Algoritma untuk key JWT baru (EdDSA atau RS256) dan umur key sebelum dirotasi
+	JWTSigningAlg  string
+	JWTKeyRotation time.Duration
+
+	// Masa simpan file export data dan umur link download-nya
+	DataExportTTL     time.Duration
+	DataExportLinkTTL time.Duration
+
+	// Masa tenggang sebelum akun yang dihapus user dihapus permanen
+	AccountDeletionGrace time.Duration
+	// Umur maksimal session untuk menghapus akun tanpa password (akun dari identity provider)
+	RecentLoginWindow time.Duration
+
+	// Provider OpenID Connect berdasarkan nama di URL, misal "google"
+	OIDCProviders map[string]domain.IdentityProvider
+}
+
 type Service struct {
 	Auth interface {
 		Login(context.Context, domain.UserLoginInput) (*domain.LoginResponse, error)
+		Refresh(context.Context, domain.RefreshTokenInput) (*domain.LoginResponse, error)
+		Logout(context.Context, *domain.TokenClaims) error
+		Authenticate(context.Context, string) (*domain.TokenClaims, error)
+		ForgotPassword(context.Context, domain.ForgotPasswordInput) error
+		ResetPassword(context.Context, domain.ResetPasswordInput) error
+		VerifyEmail(context.Context, domain.VerifyEmailInput) error
+		ResendVerification(context.Context, domain.ResendVerificationInput) error
+		UnlockAccount(context.Context, int64) error
+		LoginMFA(context.Context, domain.MFALoginInput) (*domain.LoginResponse, error)
+		StartOIDC(context.Context, string) (*domain.OIDCAuthorization, error)
+		LoginOIDC(context.Context, domain.OIDCCallbackInput) (*domain.LoginResponse, error)
+	}
+
+	DataExports interface {
+		Request(context.Context, int64) (*domain.DataExport, error)
+		Get(context.Context, int64, int64) (*domain.DataExport, error)
+		ProcessPending(context.Context) error
+		PurgeExpired(context.Context) error
 	}
+
+	Audit interface {
+		List(context.Context, domain.AuditFilter) (*domain.Page[*domain.AuditEvent], error)
+	}
+
+	Sessions interface {
+		List(context.Context, *domain.TokenClaims) ([]*domain.Session, error)
+		Revoke(context.Context, int64, int64) error
+		RevokeAll(context.Context, int64) error
+	}
+
+	SigningKeys interface {
+		Rotate(context.Context) error
+		JWKS() domain.JWKS
+	}
+
+	TwoFactor interface {
+		EnrollTOTP(context.Context, int64) (*domain.TOTPEnrollment, error)
+		ConfirmTOTP(context.Context, int64, domain.TOTPCodeInput) (*domain.RecoveryCodes, error)
+		DisableTOTP(context.Context, int64, domain.DisableTOTPInput) error
+		RegenerateRecoveryCodes(context.Context, int64, domain.TOTPCodeInput) (*domain.RecoveryCodes, error)
+	}
+	APIKeys interface {
+		Create(context.Context, int64, domain.CreateAPIKeyInput) (*domain.CreatedAPIKey, error)
+		List(context.Context, int64) ([]*domain.APIKey, error)
+		Revoke(context.Context, int64, int64) error
+		AuthenticateAPIKey(context.Context, string) (*domain.TokenClaims, error)
+	}
+
 	Users interface {
-		GetPaginated(context.Context, int, int) ([]*domain.User, error)
+		GetPaginated(context.Context, domain.PageRequest) (*domain.Page[*domain.User], error)
 		GetByID(context.Context, int64) (*domain.User, error)
 		GetByEmail(context.Context, string) (*domain.User, error)
 		Create(context.Context, domain.UserCreateInput) (*domain.UserResponse, error)
 		Update(context.Context, int64, domain.UserUpdateInput) (*domain.UserResponse, error)
 		UpdatePassword(context.Context, int64, string) (*domain.UserResponse, error)
 		UpdateAvatar(context.Context, int64, io.Reader) (string, error)
+		UpdateRole(context.Context, int64, domain.UserRoleInput) (*domain.UserResponse, error)
 		Delete(context.Context, int64) error
+		ScheduleDeletion(context.Context, int64, domain.DeleteAccountInput) (*domain.User, error)
+		PurgeDeleted(context.Context) error
 	}
 
 	Diary interface {
 		GetSummaryByUserId(context.Context, int64, time.Time) (*domain.DailySummary, error)
+		List(context.Context, domain.DiaryFilter) (*domain.Page[*domain.FoodDiary], error)
 		GetDiaryByDiaryId(context.Context, int64) (*domain.FoodDiary, error)
 		GetDiaryWithUserId(context.Context, int64, int64) (*domain.FoodDiary, error)
 		Create(context.Context, *domain.DiaryCreateInput) (*domain.FoodDiary, error)
@@ -35,12 +144,37 @@ type Service struct {
 	}
 
 	Foods interface {
-		Search(context.Context, domain.FoodFilter) ([]*domain.Food, error)
+		Search(context.Context, domain.FoodFilter) (*domain.Page[*domain.Food], error)
 		GetPaginated(context.Context, int, int) ([]*domain.Food, error)
-		GetByID(context.Context, int64) (*domain.Food, error)
-		Create(context.Context, *domain.CreateFoodInput) (*domain.Food, error)
-		Update(context.Context, int64, domain.UpdateFoodInput) (*domain.Food, error)
-		Delete(context.Context, int64) error
+		GetByID(context.Context, domain.FoodViewer, int64) (*domain.Food, error)
+		GetByBarcode(context.Context, domain.FoodViewer, string) (*domain.Food, error)
+		Create(context.Context, domain.FoodViewer, *domain.CreateFoodInput) (*domain.Food, error)
+		Update(context.Context, domain.FoodViewer, int64, domain.UpdateFoodInput) (*domain.Food, error)
+		Delete(context.Context, domain.FoodViewer, int64) error
+		AddPortion(context.Context, domain.FoodViewer, int64, domain.FoodPortion) (*domain.FoodPortion, error)
+		DeletePortion(context.Context, domain.FoodViewer, int64, int64) error
+		ListRevisions(context.Context, domain.FoodViewer, int64) ([]*domain.FoodRevision, error)
+		GetRevision(context.Context, domain.FoodViewer, int64, int) (*domain.FoodRevision, error)
+		DiffRevisions(context.Context, domain.FoodViewer, int64, int, int) (*domain.FoodRevisionDiff, error)
+		Rollback(context.Context, domain.FoodViewer, int64, int) (*domain.Food, error)
+		FindDuplicates(context.Context, domain.FoodDuplicateFilter) ([]*domain.FoodDuplicate, error)
+		Merge(context.Context, *domain.FoodMergeInput) (*domain.FoodMergeResult, error)
+		Submit(context.Context, domain.FoodViewer, int64) (*domain.Food, error)
+		Publish(context.Context, int64) (*domain.Food, error)
+		Reject(context.Context, int64) (*domain.Food, error)
+	}
+
+	FoodImports interface {
+		Request(context.Context, *domain.FoodImportInput) (*domain.FoodImport, error)
+		Get(context.Context, int64) (*domain.FoodImport, error)
+		ListErrors(context.Context, domain.FoodImportErrorFilter) ([]*domain.FoodImportError, error)
+		ProcessPending(context.Context) error
+	}
+
+	Recipes interface {
+		Get(context.Context, domain.FoodViewer, int64) (*domain.Recipe, error)
+		Create(context.Context, domain.FoodViewer, *domain.RecipeInput) (*domain.Recipe, error)
+		Update(context.Context, domain.FoodViewer, int64, *domain.RecipeInput) (*domain.Recipe, error)
 	}
 
 	Health interface {
@@ -48,12 +182,20 @@ type Service struct {
 	}
 }
 
-func NewService(store store.Storage, validator validator.Validate, storage domain.FileStorage) Service {
+func NewService(store store.Storage, validator validator.Validate, storage domain.FileStorage, cfg Config) Service {
 	return Service{
-		Auth:   &AuthService{store, validator},
-		Users:  &UserService{store, validator, storage},
-		Diary:  &DiaryService{store, validator},
-		Foods:  &FoodService{store, validator},
-		Health: &UserHealthService{store, validator},
+		Auth:        &AuthService{store, validator, cfg},
+		Users:       &UserService{store, validator, storage, cfg},
+		TwoFactor:   &TwoFactorService{store, validator, cfg},
+		APIKeys:     &APIKeyService{store, validator},
+		Sessions:    &SessionService{store},
+		Audit:       &AuditService{store, validator},
+		DataExports: &DataExportService{store, storage, cfg},
+		SigningKeys: &SigningKeyService{store, cfg},
+		Diary:       &DiaryService{store, validator, cfg},
+		Foods:       &FoodService{store, validator},
+		FoodImports: &FoodImportService{store, validator, storage},
+		Recipes:     &RecipeService{store, validator},
+		Health:      &UserHealthService{store, validator},
 	}
 }
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/MyFirstGo/internal/store"
+)
+
+type SessionService struct {
+	store store.Storage
+}
+
+// List mengembalikan session aktif milik user, session yang sedang dipakai ditandai Current
+func (s *SessionService) List(ctx context.Context, claims *domain.TokenClaims) ([]*domain.Session, error) {
+	sessions, err := s.store.Sessions.ListActiveByUser(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		userAgent := ""
+		if session.UserAgent != nil {
+			userAgent = *session.UserAgent
+		}
+
+		session.Device = helper.DeviceName(userAgent)
+		session.Current = session.ID == claims.SessionID
+	}
+
+	return sessions, nil
+}
+
+// Revoke mencabut satu session. Access token session tersebut langsung ditolak
+// AuthMiddleware dan refresh token-nya tidak bisa dipakai lagi.
+func (s *SessionService) Revoke(ctx context.Context, userID, sessionID int64) error {
+	if err := s.store.Sessions.RevokeForUser(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditSessionRevoke, domain.AuditTargetSession, sessionID, nil)
+
+	return nil
+}
+
+// RevokeAll mengeluarkan user dari semua perangkat, termasuk session saat ini
+func (s *SessionService) RevokeAll(ctx context.Context, userID int64) error {
+	if err := s.store.Sessions.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditSessionRevokeAll, domain.AuditTargetUser, userID, nil)
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/MyFirstGo/internal/store"
+)
+
+// Key baru dipublikasikan di JWKS selama ini sebelum mulai dipakai menandatangani,
+// supaya service lain yang meng-cache JWKS sudah mengenalnya
+const signingKeyPrepublish = time.Hour
+
+type SigningKeyService struct {
+	store  store.Storage
+	config Config
+}
+
+// Rotate membuat key berikutnya jika key yang ada akan pensiun dalam waktu dekat,
+// lalu memuat ulang semua key yang masih berlaku ke memori.
+func (s *SigningKeyService) Rotate(ctx context.Context) error {
+	if err := s.store.SigningKeys.DeleteExpired(ctx); err != nil {
+		return err
+	}
+
+	keys, err := s.store.SigningKeys.ListValid(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	horizon := now.Add(signingKeyPrepublish)
+
+	activatesAt := now
+	for _, k := range keys {
+		if k.RetiresAt.After(activatesAt) {
+			activatesAt = k.RetiresAt
+		}
+	}
+
+	if !activatesAt.After(horizon) {
+		key, err := helper.GenerateSigningKey(s.config.JWTSigningAlg)
+		if err != nil {
+			return err
+		}
+
+		key.ActivatesAt = activatesAt
+		key.RetiresAt = activatesAt.Add(s.config.JWTKeyRotation)
+		// Token yang ditandatangani tepat sebelum pensiun harus tetap bisa diverifikasi
+		key.ExpiresAt = key.RetiresAt.Add(max(s.config.AccessTokenTTL, s.config.MFAChallengeTTL))
+
+		created, err := s.store.SigningKeys.CreateIfUncovered(ctx, key, horizon)
+		if err != nil {
+			return err
+		}
+
+		if created {
+			keys = append(keys, key)
+		} else if keys, err = s.store.SigningKeys.ListValid(ctx); err != nil {
+			return err
+		}
+	}
+
+	return helper.LoadSigningKeys(keys)
+}
+
+func (s *SigningKeyService) JWKS() domain.JWKS {
+	return helper.PublicJWKS()
+}
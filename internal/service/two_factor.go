@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/MyFirstGo/internal/store"
+	"github.com/MyFirstGo/pkg/totp"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const recoveryCodeCount = 10
+
+type TwoFactorService struct {
+	store     store.Storage
+	validator validator.Validate
+	config    Config
+}
+
+// EnrollTOTP membuat secret baru. 2FA belum aktif sampai user mengonfirmasi dengan kode pertama.
+func (s *TwoFactorService) EnrollTOTP(ctx context.Context, userID int64) (*domain.TOTPEnrollment, error) {
+	user, err := s.store.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return nil, domain.ErrTOTPEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := helper.Encrypt([]byte(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.TwoFactor.SetPendingTOTP(ctx, userID, encrypted); err != nil {
+		return nil, err
+	}
+
+	return &domain.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.config.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *TwoFactorService) ConfirmTOTP(ctx context.Context, userID int64, payload domain.TOTPCodeInput) (*domain.RecoveryCodes, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	state, err := s.store.TwoFactor.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.EnabledAt != nil {
+		return nil, domain.ErrTOTPEnabled
+	}
+
+	if state.Secret == nil {
+		return nil, domain.ErrTOTPNotEnabled
+	}
+
+	if err := verifyTOTP(ctx, s.store, userID, state, payload.Code); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.TwoFactor.EnableTOTP(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditTwoFactorEnable, domain.AuditTargetUser, userID, nil)
+
+	return s.issueRecoveryCodes(ctx, userID)
+}
+
+func (s *TwoFactorService) DisableTOTP(ctx context.Context, userID int64, payload domain.DisableTOTPInput) error {
+	if err := s.validator.Struct(payload); err != nil {
+		return err
+	}
+
+	password, err := s.store.Users.GetPasswordHash(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(password), []byte(payload.Password)); err != nil {
+		return domain.ErrInvalidCredentials
+	}
+
+	state, err := s.store.TwoFactor.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if state.EnabledAt == nil {
+		return domain.ErrTOTPNotEnabled
+	}
+
+	if err := verifyTOTP(ctx, s.store, userID, state, payload.Code); err != nil {
+		return err
+	}
+
+	if err := s.store.TwoFactor.DisableTOTP(ctx, userID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditTwoFactorDisable, domain.AuditTargetUser, userID, nil)
+
+	return nil
+}
+
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID int64, payload domain.TOTPCodeInput) (*domain.RecoveryCodes, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	state, err := s.store.TwoFactor.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.EnabledAt == nil {
+		return nil, domain.ErrTOTPNotEnabled
+	}
+
+	if err := verifyTOTP(ctx, s.store, userID, state, payload.Code); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, userID)
+}
+
+func (s *TwoFactorService) issueRecoveryCodes(ctx context.Context, userID int64) (*domain.RecoveryCodes, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([][]byte, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, helper.HashToken(normalizeRecoveryCode(code)))
+	}
+
+	if err := s.store.TwoFactor.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return &domain.RecoveryCodes{Codes: codes}, nil
+}
+
+// verifyTOTP mengecek kode dan menolak kode yang sama dipakai dua kali
+func verifyTOTP(ctx context.Context, st store.Storage, userID int64, state *domain.TOTPSecret, code string) error {
+	secret, err := helper.Decrypt(state.Secret)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totp.Validate(string(secret), code, time.Now(), 1)
+	if !ok {
+		return domain.ErrInvalidMFACode
+	}
+
+	fresh, err := st.TwoFactor.UseTOTPStep(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+
+	if !fresh {
+		return domain.ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+func useRecoveryCode(ctx context.Context, st store.Storage, userID int64, code string) error {
+	err := st.TwoFactor.ConsumeRecoveryCode(ctx, userID, helper.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return domain.ErrInvalidMFACode
+		}
+		return err
+	}
+
+	return nil
+}
+
+// generateRecoveryCode membuat kode berformat xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+
+	return code[:5] + "-" + code[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
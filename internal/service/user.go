@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"image/jpeg"
 	"io"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/MyFirstGo/internal/domain"
@@ -22,21 +24,11 @@ type UserService struct {
 	store     store.Storage
 	validator validator.Validate
 	storage   domain.FileStorage
+	config    Config
 }
 
-func (s *UserService) GetPaginated(ctx context.Context, size, page int) ([]*domain.User, error) {
-
-	if page < 1 {
-		page = 1
-	}
-
-	if size < 1 || size > 100 {
-		size = 10
-	}
-
-	offset := (page - 1) * size
-
-	return s.store.Users.GetPaginated(ctx, size, offset)
+func (s *UserService) GetPaginated(ctx context.Context, p domain.PageRequest) (*domain.Page[*domain.User], error) {
+	return s.store.Users.GetPaginated(ctx, normalizePage(p))
 }
 
 func (s *UserService) GetByID(ctx context.Context, id int64) (*domain.User, error) {
@@ -90,8 +82,16 @@ func (s *UserService) Create(ctx context.Context, payload domain.UserCreateInput
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// User sudah tersimpan, kegagalan kirim hanya di-log karena email bisa diminta ulang
+	// lewat /verify/resend
+	if err := sendVerificationEmail(ctx, s.store, s.config, user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+
 	res := mapper.UserToUserResponse(user)
 
+	recordAudit(ctx, s.store, domain.AuditUserCreate, domain.AuditTargetUser, user.ID, auditDiff(struct{}{}, res))
+
 	return res, nil
 }
 
@@ -105,12 +105,17 @@ func (s *UserService) Update(ctx context.Context, id int64, payload domain.UserU
 		return nil, err
 	}
 
+	before := *user
+
 	if payload.Username != nil {
 		user.Username = *payload.Username
 	}
 
-	if payload.Email != nil {
+	emailChanged := false
+	if payload.Email != nil && !strings.EqualFold(*payload.Email, user.Email) {
 		user.Email = *payload.Email
+		user.EmailVerifiedAt = nil
+		emailChanged = true
 	}
 
 	if payload.Weight != nil {
@@ -134,9 +139,20 @@ func (s *UserService) Update(ctx context.Context, id int64, payload domain.UserU
 	}
 
 	if err = s.store.Users.Update(ctx, user); err != nil {
+		if helper.IsDuplicateKeyError(err) {
+			return nil, domain.ErrDuplicateEmail
+		}
 		return nil, err
 	}
 
+	if emailChanged {
+		if err := sendVerificationEmail(ctx, s.store, s.config, user); err != nil {
+			log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+
+	recordAudit(ctx, s.store, domain.AuditUserUpdate, domain.AuditTargetUser, user.ID, auditDiff(&before, user))
+
 	res := mapper.UserToUserResponse(user)
 
 	return res, nil
@@ -153,8 +169,14 @@ func (s *UserService) UpdatePassword(ctx context.Context, id int64, newPassword
 		return nil, err
 	}
 
+	if err := s.store.Users.UpdatePassword(ctx, id, string(hashedPassword)); err != nil {
+		return nil, err
+	}
+
 	user.Password = string(hashedPassword)
 
+	recordAudit(ctx, s.store, domain.AuditPasswordChange, domain.AuditTargetUser, id, nil)
+
 	return mapper.UserToUserResponse(user), nil
 }
 
@@ -186,6 +208,36 @@ func (s *UserService) UpdateAvatar(ctx context.Context, userID int64, file io.Re
 	return objectName, nil
 }
 
+func (s *UserService) UpdateRole(ctx context.Context, id int64, payload domain.UserRoleInput) (*domain.UserResponse, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Users.UpdateRole(ctx, id, payload.Role); err != nil {
+		return nil, err
+	}
+
+	// Role ada di access token, session lama dicabut supaya permission lama tidak terbawa
+	if user.Role != payload.Role {
+		if err := s.store.Sessions.RevokeAllForUser(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	recordAudit(ctx, s.store, domain.AuditUserRoleChange, domain.AuditTargetUser, id, map[string]domain.AuditChange{
+		"role": {From: user.Role, To: payload.Role},
+	})
+
+	user.Role = payload.Role
+
+	return mapper.UserToUserResponse(user), nil
+}
+
 func (s *UserService) Delete(ctx context.Context, id int64) error {
 	err := s.store.Users.Delete(ctx, id)
 	if err != nil {
@@ -200,5 +252,136 @@ func (s *UserService) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
+	recordAudit(ctx, s.store, domain.AuditUserDelete, domain.AuditTargetUser, id, nil)
+
+	return nil
+}
+
+// ScheduleDeletion menjadwalkan penghapusan permanen akun setelah masa tenggang.
+// Semua session langsung dicabut, dan login kembali sebelum jadwal akan membatalkannya.
+func (s *UserService) ScheduleDeletion(ctx context.Context, userID int64, payload domain.DeleteAccountInput) (*domain.User, error) {
+	if err := s.validator.Struct(payload); err != nil {
+		return nil, err
+	}
+
+	user, err := s.store.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeletionScheduledAt != nil {
+		return nil, domain.ErrDeletionScheduled
+	}
+
+	if err := s.confirmAccountOwner(ctx, userID, payload); err != nil {
+		return nil, err
+	}
+
+	at := time.Now().Add(s.config.AccountDeletionGrace).Truncate(time.Second)
+	if err := s.store.Users.ScheduleDeletion(ctx, userID, at); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Sessions.RevokeAllForUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditUserDeleteSchedule, domain.AuditTargetUser, userID, map[string]domain.AuditChange{
+		"deletion_scheduled_at": {From: nil, To: at},
+	})
+
+	user.DeletionScheduledAt = &at
+
+	if err := s.store.Outbox.Enqueue(ctx, accountDeletionScheduledEmail(user, at.Format("02 Jan 2006 15:04 MST"))); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// confirmAccountOwner memeriksa password. Akun yang dibuat lewat identity provider tidak punya
+// password yang diketahui user, jadi tanpa password cukup session yang baru saja login.
+func (s *UserService) confirmAccountOwner(ctx context.Context, userID int64, payload domain.DeleteAccountInput) error {
+	if payload.Password != "" {
+		password, err := s.store.Users.GetPasswordHash(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(password), []byte(payload.Password)); err != nil {
+			return domain.ErrInvalidCredentials
+		}
+		return nil
+	}
+
+	identities, err := s.store.Identities.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(identities) == 0 {
+		return domain.ErrInvalidCredentials
+	}
+
+	session, err := s.store.Sessions.GetByID(ctx, payload.SessionID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return domain.ErrReauthRequired
+		}
+		return err
+	}
+
+	if session.UserID != userID || session.RevokedAt != nil || time.Since(session.CreatedAt) > s.config.RecentLoginWindow {
+		return domain.ErrReauthRequired
+	}
+
+	return nil
+}
+
+// PurgeDeleted menghapus permanen akun yang masa tenggangnya sudah lewat,
+// termasuk file di object storage yang tidak ikut terhapus oleh cascade database.
+// Akun yang gagal dihapus dicatat dan dicoba lagi di putaran berikutnya.
+func (s *UserService) PurgeDeleted(ctx context.Context) error {
+	users, err := s.store.Users.ListDueForPurge(ctx, 20)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if err := s.purge(ctx, user); err != nil {
+			log.Printf("Failed to purge user %d: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *UserService) purge(ctx context.Context, user *domain.User) error {
+	// Database dihapus dulu, file baru dihapus setelah commit supaya user yang
+	// membatalkan penghapusan di tengah jalan tidak kehilangan file
+	if err := s.store.Users.Purge(ctx, user.ID); err != nil {
+		// Penghapusan dibatalkan di antara list dan purge
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	prefixes := []string{
+		fmt.Sprintf("avatars/%d-", user.ID),
+		fmt.Sprintf("exports/%d/", user.ID),
+	}
+
+	for _, prefix := range prefixes {
+		if err := s.storage.DeletePrefix(ctx, prefix); err != nil {
+			log.Printf("Failed to delete objects %s of purged user %d: %v", prefix, user.ID, err)
+		}
+	}
+
+	if err := s.store.LoginAttempts.Reset(ctx, accountAttemptKey(user.Email)); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.store, domain.AuditUserPurge, domain.AuditTargetUser, user.ID, nil)
+
 	return nil
 }
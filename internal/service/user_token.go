@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/MyFirstGo/internal/store"
+)
+
+// createUserToken menerbitkan token sekali pakai baru. Token lama dengan
+// purpose yang sama dihanguskan, jadi hanya link terakhir yang berlaku.
+func createUserToken(ctx context.Context, st store.Storage, userID int64, purpose string, ttl time.Duration) (string, error) {
+	if err := st.Tokens.Invalidate(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	token, tokenHash, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := st.Tokens.Create(ctx, &domain.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func sendVerificationEmail(ctx context.Context, st store.Storage, cfg Config, user *domain.User) error {
+	token, err := createUserToken(ctx, st, user.ID, domain.TokenPurposeEmailVerification, cfg.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := linkWithToken(cfg.EmailVerificationURL, token)
+
+	return st.Outbox.Enqueue(ctx, verificationEmail(user, link, cfg.EmailVerificationTTL.String()))
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/lib/pq"
+)
+
+type APIKeyStore struct {
+	db *sql.DB
+}
+
+func (s *APIKeyStore) Create(ctx context.Context, key *domain.APIKey) error {
+	query := `
+	INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING id, created_at
+	`
+
+	return s.db.QueryRowContext(ctx, query,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		pq.Array(scopesToStrings(key.Scopes)),
+		key.ExpiresAt,
+	).Scan(
+		&key.ID,
+		&key.CreatedAt,
+	)
+}
+
+func (s *APIKeyStore) ListByUser(ctx context.Context, userID int64) ([]*domain.APIKey, error) {
+	query := `
+	SELECT id, user_id, name, prefix, scopes, last_used_at, expires_at, created_at, revoked_at
+	FROM api_keys
+	WHERE user_id = $1 AND revoked_at IS NULL
+	ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*domain.APIKey{}
+	for rows.Next() {
+		key := &domain.APIKey{}
+		var scopes []string
+		if err := rows.Scan(
+			&key.ID,
+			&key.UserID,
+			&key.Name,
+			&key.Prefix,
+			pq.Array(&scopes),
+			&key.LastUsedAt,
+			&key.ExpiresAt,
+			&key.CreatedAt,
+			&key.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		key.Scopes = stringsToScopes(scopes)
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// GetActiveByHash mengambil key yang belum dicabut/kedaluwarsa beserta role pemiliknya,
+// dipanggil di setiap request yang memakai API key.
+func (s *APIKeyStore) GetActiveByHash(ctx context.Context, keyHash []byte) (*domain.APIKey, domain.Role, error) {
+	query := `
+	SELECT k.id, k.user_id, k.name, k.prefix, k.scopes, k.last_used_at, k.expires_at, k.created_at, u.role
+	FROM api_keys k
+	JOIN users u ON u.id = k.user_id AND u.deleted_at IS NULL AND u.deletion_scheduled_at IS NULL
+	WHERE k.key_hash = $1
+		AND k.revoked_at IS NULL
+		AND (k.expires_at IS NULL OR k.expires_at > NOW())
+	`
+
+	key := &domain.APIKey{KeyHash: keyHash}
+	var scopes []string
+	var role domain.Role
+	err := s.db.QueryRowContext(ctx, query, keyHash).Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		pq.Array(&scopes),
+		&key.LastUsedAt,
+		&key.ExpiresAt,
+		&key.CreatedAt,
+		&role,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", ErrNotFound
+		}
+		return nil, "", err
+	}
+
+	key.Scopes = stringsToScopes(scopes)
+
+	return key, role, nil
+}
+
+// TouchLastUsed hanya menulis paling sering sekali per menit supaya
+// integrasi yang sering memanggil API tidak membebani database.
+func (s *APIKeyStore) TouchLastUsed(ctx context.Context, id int64) error {
+	query := `
+	UPDATE api_keys
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (s *APIKeyStore) Revoke(ctx context.Context, userID, id int64) error {
+	query := `
+	UPDATE api_keys
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	res, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func scopesToStrings(scopes []domain.Permission) []string {
+	out := make([]string, len(scopes))
+	for i, scope := range scopes {
+		out[i] = string(scope)
+	}
+	return out
+}
+
+func stringsToScopes(values []string) []domain.Permission {
+	out := make([]domain.Permission, len(values))
+	for i, v := range values {
+		out[i] = domain.Permission(v)
+	}
+	return out
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+type AuditStore struct {
+	db *sql.DB
+}
+
+func (s *AuditStore) Create(ctx context.Context, event *domain.AuditEvent) error {
+	var changes []byte
+	if len(event.Changes) > 0 {
+		var err error
+		if changes, err = json.Marshal(event.Changes); err != nil {
+			return err
+		}
+	}
+
+	query := `
+	INSERT INTO audit_events (actor_id, actor_api_key_id, action, target_type, target_id, changes, ip_address, user_agent, request_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id, created_at
+	`
+
+	return s.db.QueryRowContext(ctx, query,
+		event.ActorID,
+		event.ActorAPIKey,
+		event.Action,
+		event.TargetType,
+		event.TargetID,
+		changes,
+		event.IPAddress,
+		event.UserAgent,
+		event.RequestID,
+	).Scan(
+		&event.ID,
+		&event.CreatedAt,
+	)
+}
+
+func (s *AuditStore) List(ctx context.Context, f domain.AuditFilter) (*domain.Page[*domain.AuditEvent], error) {
+	var where strings.Builder
+	var args []any
+	argIdx := 1
+
+	where.WriteString(" WHERE 1 = 1")
+
+	if f.ActorID != nil {
+		fmt.Fprintf(&where, " AND actor_id = $%d", argIdx)
+		args = append(args, *f.ActorID)
+		argIdx++
+	}
+
+	if f.TargetType != "" {
+		fmt.Fprintf(&where, " AND target_type = $%d", argIdx)
+		args = append(args, f.TargetType)
+		argIdx++
+	}
+
+	if f.TargetID != nil {
+		fmt.Fprintf(&where, " AND target_id = $%d", argIdx)
+		args = append(args, *f.TargetID)
+		argIdx++
+	}
+
+	if f.From != nil {
+		fmt.Fprintf(&where, " AND created_at >= $%d", argIdx)
+		args = append(args, *f.From)
+		argIdx++
+	}
+
+	if f.To != nil {
+		fmt.Fprintf(&where, " AND created_at < $%d", argIdx)
+		args = append(args, *f.To)
+		argIdx++
+	}
+
+	var total *int64
+	if f.Page.WithTotal {
+		var count int64
+		queryCount := "SELECT COUNT(*) FROM audit_events" + where.String()
+		if err := s.db.QueryRowContext(ctx, queryCount, args...).Scan(&count); err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	ks, err := newKeyset(f.Page.Cursor, sortKey{"created_at", true}, sortKey{"id", true})
+	if err != nil {
+		return nil, err
+	}
+
+	if cond, cursorArgs := ks.where(argIdx); cond != "" {
+		fmt.Fprintf(&where, " AND %s", cond)
+		args = append(args, cursorArgs...)
+		argIdx += len(cursorArgs)
+	}
+
+	query := fmt.Sprintf(`
+	SELECT id, actor_id, actor_api_key_id, action, target_type, target_id, changes, ip_address, user_agent, request_id, created_at, %s
+	FROM audit_events
+	%s
+	ORDER BY %s
+	LIMIT $%d
+	`, ks.columns(), where.String(), ks.orderBy(), argIdx)
+	args = append(args, f.Page.Limit+1)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*domain.AuditEvent{}
+	var keys [][]string
+
+	for rows.Next() {
+		event := &domain.AuditEvent{}
+		key := make([]string, 2)
+		var changes []byte
+		if err := rows.Scan(
+			&event.ID,
+			&event.ActorID,
+			&event.ActorAPIKey,
+			&event.Action,
+			&event.TargetType,
+			&event.TargetID,
+			&changes,
+			&event.IPAddress,
+			&event.UserAgent,
+			&event.RequestID,
+			&event.CreatedAt,
+			&key[0],
+			&key[1],
+		); err != nil {
+			return nil, err
+		}
+
+		if changes != nil {
+			if err := json.Unmarshal(changes, &event.Changes); err != nil {
+				return nil, err
+			}
+		}
+
+		events = append(events, event)
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := keysetPage(ks, events, keys, f.Page.Limit)
+	page.Total = total
+
+	return page, nil
+}
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+type DataExportStore struct {
+	db *sql.DB
+}
+
+const dataExportColumns = `id, user_id, status, object_key, last_error, attempts, created_at, completed_at, expires_at`
+
+func (s *DataExportStore) Create(ctx context.Context, export *domain.DataExport) error {
+	query := `
+	INSERT INTO data_exports (user_id)
+	VALUES ($1)
+	RETURNING ` + dataExportColumns
+
+	return scanDataExport(s.db.QueryRowContext(ctx, query, export.UserID), export)
+}
+
+func (s *DataExportStore) GetForUser(ctx context.Context, userID, id int64) (*domain.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE id = $1 AND user_id = $2`
+
+	export := &domain.DataExport{}
+	if err := scanDataExport(s.db.QueryRowContext(ctx, query, id, userID), export); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// Claim mengambil export yang menunggu diproses, termasuk yang lease-nya habis
+// karena instance sebelumnya mati di tengah proses.
+func (s *DataExportStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.DataExport, error) {
+	query := `
+	UPDATE data_exports
+		SET status = 'processing', attempts = attempts + 1, locked_until = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM data_exports
+			WHERE status = 'pending' OR (status = 'processing' AND locked_until < NOW())
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + dataExportColumns
+
+	return s.queryDataExports(ctx, query, limit, lease.Seconds())
+}
+
+func (s *DataExportStore) MarkCompleted(ctx context.Context, id int64, objectKey string, expiresAt time.Time) error {
+	query := `
+	UPDATE data_exports
+		SET status = 'completed', object_key = $2, expires_at = $3, completed_at = NOW(), locked_until = NULL, last_error = NULL
+		WHERE id = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id, objectKey, expiresAt)
+	return err
+}
+
+// MarkFailed mengembalikan export ke antrean, atau menandainya gagal permanen jika retry false
+func (s *DataExportStore) MarkFailed(ctx context.Context, id int64, exportErr error, retry bool) error {
+	status := domain.ExportFailed
+	if retry {
+		status = domain.ExportPending
+	}
+
+	query := `
+	UPDATE data_exports
+		SET status = $2, last_error = $3, locked_until = NULL
+		WHERE id = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id, status, exportErr.Error())
+	return err
+}
+
+func (s *DataExportStore) ListExpired(ctx context.Context, limit int) ([]*domain.DataExport, error) {
+	query := `
+	SELECT ` + dataExportColumns + `
+	FROM data_exports
+	WHERE status = 'completed' AND expires_at <= NOW()
+	ORDER BY id
+	LIMIT $1
+	`
+
+	return s.queryDataExports(ctx, query, limit)
+}
+
+func (s *DataExportStore) MarkExpired(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE data_exports SET status = 'expired', object_key = NULL WHERE id = $1`, id)
+	return err
+}
+
+func (s *DataExportStore) queryDataExports(ctx context.Context, query string, args ...any) ([]*domain.DataExport, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exports []*domain.DataExport
+	for rows.Next() {
+		export := &domain.DataExport{}
+		if err := scanDataExport(rows, export); err != nil {
+			return nil, err
+		}
+		exports = append(exports, export)
+	}
+
+	return exports, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDataExport(row rowScanner, export *domain.DataExport) error {
+	return row.Scan(
+		&export.ID,
+		&export.UserID,
+		&export.Status,
+		&export.ObjectKey,
+		&export.LastError,
+		&export.Attempts,
+		&export.CreatedAt,
+		&export.CompletedAt,
+		&export.ExpiresAt,
+	)
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/MyFirstGo/internal/domain"
@@ -15,20 +16,36 @@ type DiaryStore struct {
 }
 
 func (s *DiaryStore) GetSummary(ctx context.Context, userID int64, date time.Time) (*domain.DailySummary, error) {
-	query := `
+	// Nutrient disimpan per serving. amount_consumed sudah dalam serving_unit food, entry dengan
+	// portion memakai quantity x berat portion dibagi berat serving dalam gram.
+	// Serving dan nutrient diambil dari revisi food saat entry dicatat, atau food saat ini
+	// untuk entry tanpa revisi.
+	servings := fmt.Sprintf(
+		"CASE WHEN fp.id IS NOT NULL THEN fd.quantity * fp.grams / NULLIF(%[1]s * %[2]s, 0) ELSE fd.amount_consumed / NULLIF(%[1]s, 0) END",
+		"COALESCE(fr.serving_size, f.serving_size)",
+		gramsFactor("COALESCE(fr.serving_unit, f.serving_unit)", "CASE WHEN fr.id IS NULL THEN f.density ELSE fr.density END"),
+	)
+
+	query := fmt.Sprintf(`
         SELECT
-            COALESCE(SUM(CASE WHEN n.name = 'Caloric Value' THEN (fd.amount_consumed / NULLIF(f.serving_size, 0)) * fn.amount END), 0) as calories,
-            COALESCE(SUM(CASE WHEN n.name = 'Protein' THEN (fd.amount_consumed / NULLIF(f.serving_size, 0)) * fn.amount END), 0) as protein,
-            COALESCE(SUM(CASE WHEN n.name = 'Carbohydrates' THEN (fd.amount_consumed / NULLIF(f.serving_size, 0)) * fn.amount END), 0) as carbs,
-            COALESCE(SUM(CASE WHEN n.name = 'Fat' THEN (fd.amount_consumed / NULLIF(f.serving_size, 0)) * fn.amount END), 0) as fat
+            COALESCE(SUM(CASE WHEN n.name = 'Caloric Value' THEN %[1]s * fn.amount END), 0) as calories,
+            COALESCE(SUM(CASE WHEN n.name = 'Protein' THEN %[1]s * fn.amount END), 0) as protein,
+            COALESCE(SUM(CASE WHEN n.name = 'Carbohydrates' THEN %[1]s * fn.amount END), 0) as carbs,
+            COALESCE(SUM(CASE WHEN n.name = 'Fat' THEN %[1]s * fn.amount END), 0) as fat
         FROM food_diaries fd
         JOIN foods f ON fd.food_id = f.id AND f.deleted_at IS NULL
-        JOIN food_nutrients fn ON f.id = fn.food_id
+        LEFT JOIN food_portions fp ON fp.id = fd.portion_id
+        LEFT JOIN food_revisions fr ON fr.id = fd.food_revision_id
+        JOIN LATERAL (
+            SELECT frn.nutrient_id, frn.amount FROM food_revision_nutrients frn WHERE frn.revision_id = fr.id
+            UNION ALL
+            SELECT fn.nutrient_id, fn.amount FROM food_nutrients fn WHERE fn.food_id = f.id AND fr.id IS NULL
+        ) fn ON TRUE
         JOIN nutrients n ON fn.nutrient_id = n.id
         WHERE fd.user_id = $1
             AND DATE(fd.consumed_at) = $2
             AND fd.deleted_at IS NULL
-    `
+    `, servings)
 
 	summary := &domain.DailySummary{
 		Entries: []domain.FoodDiary{},
@@ -53,6 +70,10 @@ func (s *DiaryStore) GetEntries(ctx context.Context, userID int64, date time.Tim
         SELECT
             fd.id,
             fd.amount_consumed,
+            fd.quantity,
+            fd.portion_id,
+            fp.name AS portion_name,
+            fr.revision AS food_revision,
             fd.consumed_at,
             fd.meal_type,
             fd.created_at,
@@ -60,6 +81,8 @@ func (s *DiaryStore) GetEntries(ctx context.Context, userID int64, date time.Tim
             f.name as food_name
         FROM food_diaries fd
         JOIN foods f ON f.id = fd.food_id
+        LEFT JOIN food_portions fp ON fp.id = fd.portion_id
+        LEFT JOIN food_revisions fr ON fr.id = fd.food_revision_id
         WHERE fd.user_id = $1
           AND DATE(fd.consumed_at) = $2
           AND fd.deleted_at IS NULL
@@ -78,6 +101,10 @@ func (s *DiaryStore) GetEntries(ctx context.Context, userID int64, date time.Tim
 		err = rows.Scan(
 			&entry.ID,
 			&entry.AmountConsumed,
+			&entry.Quantity,
+			&entry.PortionID,
+			&entry.PortionName,
+			&entry.FoodRevision,
 			&entry.ConsumedAt,
 			&entry.MealType,
 			&entry.CreatedAt,
@@ -93,12 +120,189 @@ func (s *DiaryStore) GetEntries(ctx context.Context, userID int64, date time.Tim
 	return entries, nil
 }
 
+// GetAllByUser mengambil seluruh riwayat diary user, dipakai untuk export data
+func (s *DiaryStore) GetAllByUser(ctx context.Context, userID int64) ([]*domain.FoodDiary, error) {
+	query := `
+        SELECT
+            fd.id,
+            fd.user_id,
+            fd.food_id,
+            fd.amount_consumed,
+            fd.quantity,
+            fd.portion_id,
+            fp.name AS portion_name,
+            fr.revision AS food_revision,
+            fd.consumed_at,
+            fd.meal_type,
+            fd.created_at,
+            fd.updated_at,
+            f.name as food_name
+        FROM food_diaries fd
+        JOIN foods f ON f.id = fd.food_id
+        LEFT JOIN food_portions fp ON fp.id = fd.portion_id
+        LEFT JOIN food_revisions fr ON fr.id = fd.food_revision_id
+        WHERE fd.user_id = $1
+          AND fd.deleted_at IS NULL
+        ORDER BY fd.consumed_at
+    `
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*domain.FoodDiary{}
+
+	for rows.Next() {
+		var entry domain.FoodDiary
+		err = rows.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.FoodID,
+			&entry.AmountConsumed,
+			&entry.Quantity,
+			&entry.PortionID,
+			&entry.PortionName,
+			&entry.FoodRevision,
+			&entry.ConsumedAt,
+			&entry.MealType,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+			&entry.FoodName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// List mengambil riwayat diary user dari yang terbaru dengan paginasi keyset
+func (s *DiaryStore) List(ctx context.Context, f domain.DiaryFilter) (*domain.Page[*domain.FoodDiary], error) {
+	var where strings.Builder
+	args := []any{f.UserID}
+	argIdx := 2
+
+	where.WriteString(" WHERE fd.user_id = $1 AND fd.deleted_at IS NULL")
+
+	if f.From != nil {
+		fmt.Fprintf(&where, " AND fd.consumed_at >= $%d", argIdx)
+		args = append(args, *f.From)
+		argIdx++
+	}
+
+	if f.To != nil {
+		fmt.Fprintf(&where, " AND fd.consumed_at < $%d", argIdx)
+		args = append(args, *f.To)
+		argIdx++
+	}
+
+	var total *int64
+	if f.Page.WithTotal {
+		var count int64
+		queryCount := "SELECT COUNT(*) FROM food_diaries fd" + where.String()
+		if err := s.db.QueryRowContext(ctx, queryCount, args...).Scan(&count); err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	ks, err := newKeyset(f.Page.Cursor, sortKey{"fd.consumed_at", true}, sortKey{"fd.id", true})
+	if err != nil {
+		return nil, err
+	}
+
+	if cond, cursorArgs := ks.where(argIdx); cond != "" {
+		fmt.Fprintf(&where, " AND %s", cond)
+		args = append(args, cursorArgs...)
+		argIdx += len(cursorArgs)
+	}
+
+	query := fmt.Sprintf(`
+        SELECT
+            fd.id,
+            fd.user_id,
+            fd.food_id,
+            fd.amount_consumed,
+            fd.quantity,
+            fd.portion_id,
+            fp.name AS portion_name,
+            fr.revision AS food_revision,
+            fd.consumed_at,
+            fd.meal_type,
+            fd.created_at,
+            fd.updated_at,
+            f.name as food_name,
+            %s
+        FROM food_diaries fd
+        JOIN foods f ON f.id = fd.food_id
+        LEFT JOIN food_portions fp ON fp.id = fd.portion_id
+        LEFT JOIN food_revisions fr ON fr.id = fd.food_revision_id
+        %s
+        ORDER BY %s
+        LIMIT $%d
+    `, ks.columns(), where.String(), ks.orderBy(), argIdx)
+	args = append(args, f.Page.Limit+1)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*domain.FoodDiary{}
+	var keys [][]string
+
+	for rows.Next() {
+		var entry domain.FoodDiary
+		key := make([]string, 2)
+		err = rows.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.FoodID,
+			&entry.AmountConsumed,
+			&entry.Quantity,
+			&entry.PortionID,
+			&entry.PortionName,
+			&entry.FoodRevision,
+			&entry.ConsumedAt,
+			&entry.MealType,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+			&entry.FoodName,
+			&key[0],
+			&key[1],
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := keysetPage(ks, entries, keys, f.Page.Limit)
+	page.Total = total
+
+	return page, nil
+}
+
 func (s *DiaryStore) GetUserEntry(ctx context.Context, userID, entryID int64) (*domain.FoodDiary, error) {
 	query := `
 	SELECT
 		fd.user_id,
 		fd.food_id,
 		fd.amount_consumed,
+		fd.quantity,
+		fd.portion_id,
+		fp.name AS portion_name,
+		fr.revision AS food_revision,
 		fd.consumed_at,
 		fd.meal_type,
 		f.name,
@@ -106,6 +310,8 @@ func (s *DiaryStore) GetUserEntry(ctx context.Context, userID, entryID int64) (*
 		fd.updated_at
 	FROM food_diaries fd
 	JOIN foods f ON fd.food_id = f.id
+	LEFT JOIN food_portions fp ON fp.id = fd.portion_id
+	LEFT JOIN food_revisions fr ON fr.id = fd.food_revision_id
 	WHERE fd.id = $1 AND fd.deleted_at IS NULL AND fd.user_id = $2
 	`
 
@@ -115,6 +321,10 @@ func (s *DiaryStore) GetUserEntry(ctx context.Context, userID, entryID int64) (*
 		&diary.UserID,
 		&diary.FoodID,
 		&diary.AmountConsumed,
+		&diary.Quantity,
+		&diary.PortionID,
+		&diary.PortionName,
+		&diary.FoodRevision,
 		&diary.ConsumedAt,
 		&diary.MealType,
 		&diary.FoodName,
@@ -138,6 +348,10 @@ func (s *DiaryStore) GetEntry(ctx context.Context, entryID int64) (*domain.FoodD
 		fd.user_id,
 		fd.food_id,
 		fd.amount_consumed,
+		fd.quantity,
+		fd.portion_id,
+		fp.name AS portion_name,
+		fr.revision AS food_revision,
 		fd.consumed_at,
 		fd.meal_type,
 		f.name,
@@ -145,6 +359,8 @@ func (s *DiaryStore) GetEntry(ctx context.Context, entryID int64) (*domain.FoodD
 		fd.updated_at
 	FROM food_diaries fd
 	JOIN foods f ON fd.food_id = f.id
+	LEFT JOIN food_portions fp ON fp.id = fd.portion_id
+	LEFT JOIN food_revisions fr ON fr.id = fd.food_revision_id
 	WHERE fd.id = $1 AND fd.deleted_at IS NULL
 	`
 
@@ -154,6 +370,10 @@ func (s *DiaryStore) GetEntry(ctx context.Context, entryID int64) (*domain.FoodD
 		&diary.UserID,
 		&diary.FoodID,
 		&diary.AmountConsumed,
+		&diary.Quantity,
+		&diary.PortionID,
+		&diary.PortionName,
+		&diary.FoodRevision,
 		&diary.ConsumedAt,
 		&diary.MealType,
 		&diary.FoodName,
@@ -173,8 +393,8 @@ func (s *DiaryStore) GetEntry(ctx context.Context, entryID int64) (*domain.FoodD
 
 func (s *DiaryStore) Create(ctx context.Context, entry *domain.FoodDiary) error {
 	query := `
-	INSERT INTO food_diaries (user_id, food_id, amount_consumed, consumed_at, meal_type)
-	VALUES ($1, $2, $3, $4, $5)
+	INSERT INTO food_diaries (user_id, food_id, amount_consumed, consumed_at, meal_type, portion_id, quantity, food_revision_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, (SELECT id FROM food_revisions WHERE food_id = $2 AND revision = $8))
 	RETURNING id, created_at, updated_at
 	`
 
@@ -184,6 +404,9 @@ func (s *DiaryStore) Create(ctx context.Context, entry *domain.FoodDiary) error
 		entry.AmountConsumed,
 		entry.ConsumedAt,
 		entry.MealType,
+		entry.PortionID,
+		entry.Quantity,
+		entry.FoodRevision,
 	).Scan(
 		&entry.ID,
 		&entry.CreatedAt,
@@ -205,6 +428,9 @@ func (s *DiaryStore) Update(ctx context.Context, entry *domain.FoodDiary) error
 			amount_consumed = $3,
 			consumed_at = $4,
 			meal_type = $5,
+			portion_id = $6,
+			quantity = $7,
+			food_revision_id = COALESCE((SELECT id FROM food_revisions WHERE food_id = $2 AND revision = $8), food_revision_id),
 			updated_at = NOW()
 		WHERE id = $1
 	`
@@ -215,6 +441,9 @@ func (s *DiaryStore) Update(ctx context.Context, entry *domain.FoodDiary) error
 		entry.AmountConsumed,
 		entry.ConsumedAt,
 		entry.MealType,
+		entry.PortionID,
+		entry.Quantity,
+		entry.FoodRevision,
 	)
 
 	if err != nil {
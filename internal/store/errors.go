@@ -3,3 +3,9 @@ package store
 import "errors"
 
 var ErrNotFound = errors.New("resource not found")
+
+// Nama unique constraint yang perlu dibedakan saat memetakan duplicate key ke error domain
+const (
+	ConstraintFoodBarcode    = "food_barcodes_pkey"
+	ConstraintFoodExternalID = "idx_foods_external_id"
+)
@@ -0,0 +1,407 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/helper"
+	"github.com/lib/pq"
+)
+
+type FoodImportStore struct {
+	db *sql.DB
+}
+
+const foodImportColumns = `id, created_by, status, format, dry_run, mapping, object_key, total_rows, processed_rows,
+	created_rows, updated_rows, failed_rows, last_error, attempts, created_at, completed_at`
+
+func (s *FoodImportStore) Create(ctx context.Context, imp *domain.FoodImport) error {
+	mapping, err := json.Marshal(imp.Mapping)
+	if err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO food_imports (created_by, format, dry_run, mapping, object_key)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING ` + foodImportColumns
+
+	return scanFoodImport(s.db.QueryRowContext(ctx, query, imp.CreatedBy, imp.Format, imp.DryRun, mapping, imp.ObjectKey), imp)
+}
+
+func (s *FoodImportStore) GetByID(ctx context.Context, id int64) (*domain.FoodImport, error) {
+	query := `SELECT ` + foodImportColumns + ` FROM food_imports WHERE id = $1`
+
+	imp := &domain.FoodImport{}
+	if err := scanFoodImport(s.db.QueryRowContext(ctx, query, id), imp); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return imp, nil
+}
+
+// Claim mengambil import yang menunggu diproses, termasuk yang lease-nya habis. Import
+// yang diambil ulang dilanjutkan dari processed_rows.
+func (s *FoodImportStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.FoodImport, error) {
+	query := `
+	UPDATE food_imports
+		SET status = 'processing', attempts = attempts + 1, locked_until = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM food_imports
+			WHERE status = 'pending' OR (status = 'processing' AND locked_until < NOW())
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + foodImportColumns
+
+	rows, err := s.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var imports []*domain.FoodImport
+	for rows.Next() {
+		imp := &domain.FoodImport{}
+		if err := scanFoodImport(rows, imp); err != nil {
+			return nil, err
+		}
+		imports = append(imports, imp)
+	}
+
+	return imports, rows.Err()
+}
+
+func (s *FoodImportStore) SetTotalRows(ctx context.Context, id int64, total int) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE food_imports SET total_rows = $2 WHERE id = $1`, id, total)
+	return err
+}
+
+func (s *FoodImportStore) MarkCompleted(ctx context.Context, id int64) error {
+	query := `
+	UPDATE food_imports
+		SET status = 'completed', object_key = NULL, completed_at = NOW(), locked_until = NULL, last_error = NULL
+		WHERE id = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// MarkFailed mengembalikan import ke antrean, atau menandainya gagal permanen jika retry false
+func (s *FoodImportStore) MarkFailed(ctx context.Context, id int64, importErr error, retry bool) error {
+	status := domain.FoodImportFailed
+	if retry {
+		status = domain.FoodImportPending
+	}
+
+	query := `
+	UPDATE food_imports
+		SET status = $2, last_error = $3, locked_until = NULL, completed_at = CASE WHEN $4 THEN NOW() END
+		WHERE id = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id, status, importErr.Error(), !retry)
+	return err
+}
+
+func (s *FoodImportStore) ListErrors(ctx context.Context, filter domain.FoodImportErrorFilter) ([]*domain.FoodImportError, error) {
+	query := `
+	SELECT row_number, COALESCE(key, ''), message
+	FROM food_import_errors
+	WHERE import_id = $1
+	ORDER BY row_number
+	LIMIT $2 OFFSET $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, filter.ImportID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	errs := []*domain.FoodImportError{}
+	for rows.Next() {
+		e := &domain.FoodImportError{}
+		if err := rows.Scan(&e.Row, &e.Key, &e.Message); err != nil {
+			return nil, err
+		}
+		errs = append(errs, e)
+	}
+
+	return errs, rows.Err()
+}
+
+// SaveBatch menyimpan satu batch baris import bersama progress job dalam satu transaksi,
+// jadi import yang terhenti bisa dilanjutkan setelah batch terakhir. Tiap baris memakai
+// savepoint sendiri supaya baris yang gagal tidak membatalkan baris lain. Pada dry run
+// perubahan food dibatalkan, hanya error dan progress yang disimpan.
+func (s *FoodImportStore) SaveBatch(ctx context.Context, imp *domain.FoodImport, rows []*domain.FoodImportRow, lease time.Duration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT import_batch`); err != nil {
+		return err
+	}
+
+	var created, updated, failed int
+	for _, row := range rows {
+		if row.Error != "" {
+			failed++
+			continue
+		}
+
+		isNew := row.Food.ID == 0
+		if err := saveImportRow(ctx, tx, row.Food); err != nil {
+			msg, ok := importRowError(err)
+			if !ok {
+				return err
+			}
+			row.Error = msg
+			failed++
+			continue
+		}
+
+		if isNew {
+			created++
+		} else {
+			updated++
+		}
+	}
+
+	if imp.DryRun {
+		if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_batch`); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO food_import_errors (import_id, row_number, key, message)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, row := range rows {
+			if row.Error == "" {
+				continue
+			}
+			if _, err := stmt.ExecContext(ctx, imp.ID, row.Row, row.Key, row.Error); err != nil {
+				return err
+			}
+		}
+	}
+
+	queryProgress := `
+	UPDATE food_imports
+		SET processed_rows = processed_rows + $2, created_rows = created_rows + $3,
+			updated_rows = updated_rows + $4, failed_rows = failed_rows + $5,
+			locked_until = NOW() + make_interval(secs => $6)
+		WHERE id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, queryProgress, imp.ID, len(rows), created, updated, failed, lease.Seconds()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	imp.ProcessedRows += len(rows)
+	imp.CreatedRows += created
+	imp.UpdatedRows += updated
+	imp.FailedRows += failed
+
+	return nil
+}
+
+func saveImportRow(ctx context.Context, tx *sql.Tx, food *domain.Food) error {
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT import_row`); err != nil {
+		return err
+	}
+
+	var err error
+	if food.ID == 0 {
+		err = insertFood(ctx, tx, food)
+	} else {
+		err = updateFood(ctx, tx, food)
+	}
+
+	if err != nil {
+		if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_row`); rbErr != nil {
+			return rbErr
+		}
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `RELEASE SAVEPOINT import_row`)
+	return err
+}
+
+// importRowError mengubah error penyimpanan satu baris menjadi pesan untuk laporan import.
+// Error lain (misal koneksi putus) menggagalkan seluruh batch.
+func importRowError(err error) (string, bool) {
+	if errors.Is(err, ErrNotFound) {
+		return "food was deleted during the import", true
+	}
+
+	if helper.IsUniqueViolation(err, ConstraintFoodExternalID) {
+		return "external_id is already used by another food", true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Class() == "22" {
+		// Data exception, misal angka di luar batas kolom
+		return pqErr.Message, true
+	}
+
+	return "", false
+}
+
+func scanFoodImport(row rowScanner, imp *domain.FoodImport) error {
+	var mapping []byte
+
+	err := row.Scan(
+		&imp.ID,
+		&imp.CreatedBy,
+		&imp.Status,
+		&imp.Format,
+		&imp.DryRun,
+		&mapping,
+		&imp.ObjectKey,
+		&imp.TotalRows,
+		&imp.ProcessedRows,
+		&imp.CreatedRows,
+		&imp.UpdatedRows,
+		&imp.FailedRows,
+		&imp.LastError,
+		&imp.Attempts,
+		&imp.CreatedAt,
+		&imp.CompletedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(mapping, &imp.Mapping); err != nil {
+		return err
+	}
+
+	if imp.TotalRows != nil {
+		progress := 100.0
+		if *imp.TotalRows > 0 {
+			progress = math.Round(float64(imp.ProcessedRows)*10000/float64(*imp.TotalRows)) / 100
+		}
+		imp.Progress = &progress
+	}
+
+	return nil
+}
+
+// FindForImport mencari food yang cocok dengan baris import. Food berdasarkan external_id,
+// duplikat yang sudah digabung diarahkan ke food kanoniknya. Food berdasarkan nama (huruf
+// kecil) hanya dari katalog publik, satu nama bisa cocok dengan beberapa food.
+func (s *FoodStore) FindForImport(ctx context.Context, externalIDs, names []string) (map[string]*domain.Food, map[string][]*domain.Food, error) {
+	queryExternal := `
+	SELECT external_id, COALESCE(merged_into, id)
+	FROM foods
+	WHERE external_id = ANY($1) AND (deleted_at IS NULL OR merged_into IS NOT NULL)
+	ORDER BY deleted_at IS NOT NULL, id
+	`
+
+	externalFood := map[string]int64{}
+	var ids []int64
+
+	rows, err := s.db.QueryContext(ctx, queryExternal, pq.Array(externalIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for rows.Next() {
+		var externalID string
+		var id int64
+		if err := rows.Scan(&externalID, &id); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+
+		if _, ok := externalFood[externalID]; !ok {
+			externalFood[externalID] = id
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	queryNames := `
+	SELECT lower(name), id
+	FROM foods
+	WHERE lower(name) = ANY($1) AND deleted_at IS NULL AND visibility = 'public'
+	ORDER BY id
+	`
+
+	nameFoods := map[string][]int64{}
+
+	rows, err = s.db.QueryContext(ctx, queryNames, pq.Array(names))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var id int64
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, nil, err
+		}
+
+		nameFoods[name] = append(nameFoods[name], id)
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	foods, err := s.foodsByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byExternalID := make(map[string]*domain.Food, len(externalFood))
+	for externalID, id := range externalFood {
+		if f := foods[id]; f != nil {
+			byExternalID[externalID] = f
+		}
+	}
+
+	byName := make(map[string][]*domain.Food, len(nameFoods))
+	for name, foodIDs := range nameFoods {
+		for _, id := range foodIDs {
+			if f := foods[id]; f != nil {
+				byName[name] = append(byName[name], f)
+			}
+		}
+	}
+
+	return byExternalID, byName, nil
+}
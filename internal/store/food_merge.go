@@ -0,0 +1,334 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/lib/pq"
+)
+
+// FindDuplicates mencari pasangan food katalog (public atau submitted) dengan nama mirip
+// berdasarkan trigram, paling mirip lebih dulu. Jarak nutrient dihitung di service.
+func (s *FoodStore) FindDuplicates(ctx context.Context, minSimilarity float64, limit int) ([]*domain.FoodDuplicate, error) {
+	query := `
+	SELECT a.id, b.id, similarity(a.name, b.name) AS name_similarity
+	FROM foods a
+	JOIN foods b ON a.id < b.id AND a.name % b.name
+	WHERE a.deleted_at IS NULL AND b.deleted_at IS NULL
+		AND a.visibility <> 'private' AND b.visibility <> 'private'
+		AND similarity(a.name, b.name) >= $1
+	ORDER BY name_similarity DESC, a.id, b.id
+	LIMIT $2
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, minSimilarity, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type pair struct {
+		a, b       int64
+		similarity float64
+	}
+
+	var pairs []pair
+	var ids []int64
+	seen := map[int64]bool{}
+
+	for rows.Next() {
+		var p pair
+		if err := rows.Scan(&p.a, &p.b, &p.similarity); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, p)
+
+		for _, id := range []int64{p.a, p.b} {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	foods, err := s.foodsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates := make([]*domain.FoodDuplicate, 0, len(pairs))
+	for _, p := range pairs {
+		if foods[p.a] == nil || foods[p.b] == nil {
+			continue
+		}
+
+		duplicates = append(duplicates, &domain.FoodDuplicate{
+			Food:           foods[p.a],
+			Duplicate:      foods[p.b],
+			NameSimilarity: p.similarity,
+		})
+	}
+
+	return duplicates, nil
+}
+
+func (s *FoodStore) foodsByIDs(ctx context.Context, ids []int64) (map[int64]*domain.Food, error) {
+	res := make(map[int64]*domain.Food, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	query := `
+	SELECT id, name, description, serving_size, serving_unit, density, owner_id, visibility, revision, external_id, created_at, updated_at
+	FROM foods
+	WHERE id = ANY($1) AND deleted_at IS NULL
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	foods := []*domain.Food{}
+	for rows.Next() {
+		f := &domain.Food{Nutrients: []domain.NutrientAmount{}}
+		var description sql.NullString
+		if err := rows.Scan(
+			&f.ID, &f.Name, &description, &f.ServingSize, &f.ServingUnit, &f.Density,
+			&f.OwnerID, &f.Visibility, &f.Revision, &f.ExternalID, &f.CreatedAt, &f.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		f.Description = description.String
+
+		foods = append(foods, f)
+		res[f.ID] = f
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachDetails(ctx, foods); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Merge menggabungkan food duplikat ke food kanonik dalam satu transaksi: portion, entry
+// diary dan bahan resep dipindah, nama dan alias menjadi alias food kanonik, barcode dipindah
+// (atau dilepas jika food kanonik bukan publik), lalu duplikat di-soft-delete. Entry diary
+// dikonversi ke serving unit dan revisi terbaru food kanonik.
+func (s *FoodStore) Merge(ctx context.Context, canonicalID int64, duplicateIDs []int64) (*domain.FoodMergeResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Kunci semua food yang terlibat, semuanya harus masih ada
+	queryLock := `
+	SELECT id
+	FROM foods
+	WHERE (id = $1 OR id = ANY($2)) AND deleted_at IS NULL
+	FOR UPDATE
+	`
+
+	rows, err := tx.QueryContext(ctx, queryLock, canonicalID, pq.Array(duplicateIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	locked := 0
+	for rows.Next() {
+		locked++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if locked != len(duplicateIDs)+1 {
+		return nil, ErrNotFound
+	}
+
+	res := &domain.FoodMergeResult{Merged: duplicateIDs}
+
+	// Portion duplikat yang namanya belum ada di food kanonik dipindah (satu per nama),
+	// entry diary dengan portion lain dipasangkan ke portion kanonik dengan nama yang sama
+	queryPortions := `
+	UPDATE food_portions dp
+	SET food_id = $1
+	WHERE dp.food_id = ANY($2)
+		AND NOT EXISTS (SELECT 1 FROM food_portions cp WHERE cp.food_id = $1 AND lower(cp.name) = lower(dp.name))
+		AND dp.id = (SELECT min(p.id) FROM food_portions p WHERE p.food_id = ANY($2) AND lower(p.name) = lower(dp.name))
+	`
+
+	if _, err := tx.ExecContext(ctx, queryPortions, canonicalID, pq.Array(duplicateIDs)); err != nil {
+		return nil, err
+	}
+
+	queryDiaryPortions := `
+	UPDATE food_diaries fd
+	SET portion_id = cp.id
+	FROM food_portions dp
+	JOIN food_portions cp ON cp.food_id = $1 AND lower(cp.name) = lower(dp.name)
+	WHERE fd.portion_id = dp.id AND dp.food_id = ANY($2)
+	`
+
+	if _, err := tx.ExecContext(ctx, queryDiaryPortions, canonicalID, pq.Array(duplicateIDs)); err != nil {
+		return nil, err
+	}
+
+	// amount_consumed dalam serving unit revisi duplikat dikonversi lewat gram ke serving unit
+	// food kanonik. Jika satuan tidak bisa dikonversi (misal volume tanpa density), jumlah
+	// serving dipertahankan.
+	srcUnit := "COALESCE(fr.serving_unit, dup.serving_unit)"
+	queryDiaries := fmt.Sprintf(`
+	WITH moved AS (
+		SELECT fd.id,
+			COALESCE(
+				CASE WHEN %[1]s = canon.serving_unit THEN fd.amount_consumed END,
+				fd.amount_consumed * %[2]s / NULLIF(%[3]s, 0),
+				fd.amount_consumed / NULLIF(COALESCE(fr.serving_size, dup.serving_size), 0) * canon.serving_size,
+				fd.amount_consumed
+			) AS amount
+		FROM food_diaries fd
+		JOIN foods dup ON dup.id = fd.food_id
+		JOIN foods canon ON canon.id = $1
+		LEFT JOIN food_revisions fr ON fr.id = fd.food_revision_id
+		WHERE fd.food_id = ANY($2)
+	)
+	UPDATE food_diaries fd
+	SET food_id = $1,
+		amount_consumed = moved.amount,
+		food_revision_id = (
+			SELECT fr.id FROM food_revisions fr
+			JOIN foods f ON f.id = fr.food_id AND fr.revision = f.revision
+			WHERE f.id = $1
+		),
+		updated_at = NOW()
+	FROM moved
+	WHERE fd.id = moved.id
+	`,
+		srcUnit,
+		gramsFactor(srcUnit, "CASE WHEN fr.id IS NULL THEN dup.density ELSE fr.density END"),
+		gramsFactor("canon.serving_unit", "canon.density"),
+	)
+
+	diaries, err := tx.ExecContext(ctx, queryDiaries, canonicalID, pq.Array(duplicateIDs))
+	if err != nil {
+		return nil, err
+	}
+	if res.DiaryEntries, err = diaries.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	// Quantity bahan resep disimpan dengan satuannya sendiri, jadi bahan dipindah jika satuan
+	// itu bisa dikonversi ke serving_unit food kanonik: dimensi sama, atau lewat gram dengan
+	// density food kanonik
+	queryIngredients := fmt.Sprintf(`
+	UPDATE recipe_ingredients ri
+	SET food_id = $1
+	FROM foods canon
+	WHERE ri.food_id = ANY($2) AND canon.id = $1
+		AND (
+			ri.unit = canon.serving_unit
+			OR %[1]s = %[2]s
+			OR (%[3]s IS NOT NULL AND %[4]s IS NOT NULL)
+		)
+	`,
+		unitDimension("ri.unit"),
+		unitDimension("canon.serving_unit"),
+		gramsFactor("ri.unit", "canon.density"),
+		gramsFactor("canon.serving_unit", "canon.density"),
+	)
+
+	if _, err := tx.ExecContext(ctx, queryIngredients, canonicalID, pq.Array(duplicateIDs)); err != nil {
+		return nil, err
+	}
+
+	// Bahan yang tidak bisa dipindah akan menunjuk food yang dihapus dan resepnya berhenti
+	// dihitung ulang, jadi merge dibatalkan
+	queryLeftover := `
+	SELECT EXISTS (
+		SELECT 1
+		FROM recipe_ingredients ri
+		JOIN foods r ON r.id = ri.recipe_id AND r.deleted_at IS NULL
+		WHERE ri.food_id = ANY($1)
+	)
+	`
+
+	var leftover bool
+	if err := tx.QueryRowContext(ctx, queryLeftover, pq.Array(duplicateIDs)).Scan(&leftover); err != nil {
+		return nil, err
+	}
+	if leftover {
+		return nil, domain.ErrMergeIngredient
+	}
+
+	// Nama duplikat (bahasa Inggris) dan semua nama lainnya menjadi alias, kecuali yang sama
+	// dengan nama food kanonik
+	queryAliases := `
+	INSERT INTO food_names (food_id, locale, name, is_alias)
+	SELECT $1, src.locale, src.name, TRUE
+	FROM (
+		SELECT 'en' AS locale, name FROM foods WHERE id = ANY($2)
+		UNION
+		SELECT locale, name FROM food_names WHERE food_id = ANY($2)
+	) src
+	WHERE lower(src.name) <> (SELECT lower(name) FROM foods WHERE id = $1)
+	ON CONFLICT DO NOTHING
+	`
+
+	aliases, err := tx.ExecContext(ctx, queryAliases, canonicalID, pq.Array(duplicateIDs))
+	if err != nil {
+		return nil, err
+	}
+	if res.Aliases, err = aliases.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM food_names WHERE food_id = ANY($1)`, pq.Array(duplicateIDs)); err != nil {
+		return nil, err
+	}
+
+	queryBarcodes := `
+	UPDATE food_barcodes
+	SET food_id = $1
+	WHERE food_id = ANY($2)
+		AND EXISTS (SELECT 1 FROM foods WHERE id = $1 AND visibility = 'public')
+	`
+
+	if _, err := tx.ExecContext(ctx, queryBarcodes, canonicalID, pq.Array(duplicateIDs)); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM food_barcodes WHERE food_id = ANY($1)`, pq.Array(duplicateIDs)); err != nil {
+		return nil, err
+	}
+
+	queryDelete := `
+	UPDATE foods
+	SET deleted_at = NOW(), merged_into = $1
+	WHERE id = ANY($2)
+	`
+
+	if _, err := tx.ExecContext(ctx, queryDelete, canonicalID, pq.Array(duplicateIDs)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
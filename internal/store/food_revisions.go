@@ -0,0 +1,250 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/lib/pq"
+)
+
+// revisionState adalah bagian food yang diversikan
+type revisionState struct {
+	name        string
+	description sql.NullString
+	servingSize float64
+	servingUnit string
+	density     sql.NullFloat64
+	nutrients   map[int64]float64
+}
+
+func (a revisionState) equal(b revisionState) bool {
+	return a.name == b.name && a.description == b.description && a.servingSize == b.servingSize &&
+		a.servingUnit == b.servingUnit && a.density == b.density && maps.Equal(a.nutrients, b.nutrients)
+}
+
+// snapshotRevision menyimpan revisi baru jika food (setelah perubahan di tx) berbeda dari
+// revisi terakhir, misal perubahan barcode saja tidak membuat revisi. Baris food dikunci
+// supaya nomor revisi tidak bentrok, pembuat revisi diambil dari actor di context.
+func snapshotRevision(ctx context.Context, tx *sql.Tx, food *domain.Food) error {
+	queryFood := `
+	SELECT name, description, serving_size, serving_unit, density, revision
+	FROM foods
+	WHERE id = $1
+	FOR UPDATE
+	`
+
+	var current revisionState
+	var revision int
+	err := tx.QueryRowContext(ctx, queryFood, food.ID).Scan(
+		&current.name, &current.description, &current.servingSize, &current.servingUnit, &current.density, &revision,
+	)
+	if err != nil {
+		return err
+	}
+
+	current.nutrients, err = nutrientAmounts(ctx, tx, `SELECT nutrient_id, amount FROM food_nutrients WHERE food_id = $1`, food.ID)
+	if err != nil {
+		return err
+	}
+
+	if revision > 0 {
+		queryLast := `
+		SELECT id, name, description, serving_size, serving_unit, density
+		FROM food_revisions
+		WHERE food_id = $1 AND revision = $2
+		`
+
+		var last revisionState
+		var lastID int64
+		err := tx.QueryRowContext(ctx, queryLast, food.ID, revision).Scan(
+			&lastID, &last.name, &last.description, &last.servingSize, &last.servingUnit, &last.density,
+		)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		if err == nil {
+			last.nutrients, err = nutrientAmounts(ctx, tx, `SELECT nutrient_id, amount FROM food_revision_nutrients WHERE revision_id = $1`, lastID)
+			if err != nil {
+				return err
+			}
+
+			if current.equal(last) {
+				food.Revision = revision
+				return nil
+			}
+		}
+	}
+
+	var createdBy *int64
+	if actor, ok := domain.ActorFrom(ctx); ok {
+		createdBy = &actor.UserID
+	}
+
+	revision++
+
+	queryRevision := `
+	INSERT INTO food_revisions (food_id, revision, name, description, serving_size, serving_unit, density, created_by)
+	SELECT id, $2, name, description, serving_size, serving_unit, density, $3
+	FROM foods
+	WHERE id = $1
+	RETURNING id
+	`
+
+	var revisionID int64
+	if err := tx.QueryRowContext(ctx, queryRevision, food.ID, revision, createdBy).Scan(&revisionID); err != nil {
+		return err
+	}
+
+	queryNutrients := `
+	INSERT INTO food_revision_nutrients (revision_id, nutrient_id, amount)
+	SELECT $1, nutrient_id, amount
+	FROM food_nutrients
+	WHERE food_id = $2
+	`
+
+	if _, err := tx.ExecContext(ctx, queryNutrients, revisionID, food.ID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE foods SET revision = $1 WHERE id = $2`, revision, food.ID); err != nil {
+		return err
+	}
+
+	food.Revision = revision
+	return nil
+}
+
+func nutrientAmounts(ctx context.Context, tx *sql.Tx, query string, id int64) (map[int64]float64, error) {
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	amounts := map[int64]float64{}
+	for rows.Next() {
+		var nutrientID int64
+		var amount float64
+		if err := rows.Scan(&nutrientID, &amount); err != nil {
+			return nil, err
+		}
+		amounts[nutrientID] = amount
+	}
+
+	return amounts, rows.Err()
+}
+
+// ListRevisions mengambil semua revisi food, terbaru lebih dulu
+func (s *FoodStore) ListRevisions(ctx context.Context, foodID int64) ([]*domain.FoodRevision, error) {
+	query := `
+	SELECT id, food_id, revision, name, description, serving_size, serving_unit, density, created_by, created_at
+	FROM food_revisions
+	WHERE food_id = $1
+	ORDER BY revision DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, foodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*domain.FoodRevision{}
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachRevisionNutrients(ctx, revisions); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (s *FoodStore) GetRevision(ctx context.Context, foodID int64, revision int) (*domain.FoodRevision, error) {
+	query := `
+	SELECT id, food_id, revision, name, description, serving_size, serving_unit, density, created_by, created_at
+	FROM food_revisions
+	WHERE food_id = $1 AND revision = $2
+	`
+
+	rev, err := scanRevision(s.db.QueryRowContext(ctx, query, foodID, revision))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.attachRevisionNutrients(ctx, []*domain.FoodRevision{rev}); err != nil {
+		return nil, err
+	}
+
+	return rev, nil
+}
+
+func scanRevision(row rowScanner) (*domain.FoodRevision, error) {
+	rev := &domain.FoodRevision{Nutrients: []domain.NutrientAmount{}}
+	var description sql.NullString
+
+	err := row.Scan(
+		&rev.ID, &rev.FoodID, &rev.Revision, &rev.Name, &description,
+		&rev.ServingSize, &rev.ServingUnit, &rev.Density, &rev.CreatedBy, &rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rev.Description = description.String
+
+	return rev, nil
+}
+
+func (s *FoodStore) attachRevisionNutrients(ctx context.Context, revisions []*domain.FoodRevision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(revisions))
+	byID := make(map[int64]*domain.FoodRevision, len(revisions))
+	for i, rev := range revisions {
+		ids[i] = rev.ID
+		byID[rev.ID] = rev
+	}
+
+	query := `
+	SELECT frn.revision_id, n.id, n.name, n.slug, n.unit, frn.amount
+	FROM food_revision_nutrients frn
+	JOIN nutrients n ON n.id = frn.nutrient_id
+	WHERE frn.revision_id = ANY($1)
+	ORDER BY n.id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var revisionID int64
+		var n domain.NutrientAmount
+		if err := rows.Scan(&revisionID, &n.ID, &n.Name, &n.Slug, &n.Unit, &n.Amount); err != nil {
+			return err
+		}
+		byID[revisionID].Nutrients = append(byID[revisionID].Nutrients, n)
+	}
+
+	return rows.Err()
+}
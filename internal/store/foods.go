@@ -3,11 +3,15 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/pkg/converter"
 	"github.com/lib/pq"
 )
 
@@ -15,82 +19,203 @@ type FoodStore struct {
 	db *sql.DB
 }
 
-func (s *FoodStore) Search(ctx context.Context, f domain.FoodFilter) ([]*domain.Food, error) {
-	var query strings.Builder
+func (s *FoodStore) Search(ctx context.Context, f domain.FoodFilter) (*domain.Page[*domain.Food], error) {
+	var where strings.Builder
 	var args []any
 	argIdx := 1
 
-	// Base Query
-	query.WriteString(`
-        SELECT f.id, f.name, f.description, f.serving_size, f.serving_unit
-        FROM foods f
-        WHERE f.deleted_at IS NULL
-    `)
+	where.WriteString(" WHERE f.deleted_at IS NULL")
+
+	// Visibility: katalog publik, food milik sendiri, dan pengajuan untuk editor
+	visible := fmt.Sprintf("f.visibility = $%d", argIdx)
+	args = append(args, domain.FoodVisibilityPublic)
+	argIdx++
+
+	if f.Viewer.UserID != 0 {
+		visible += fmt.Sprintf(" OR f.owner_id = $%d", argIdx)
+		args = append(args, f.Viewer.UserID)
+		argIdx++
+	}
 
-	// 1. Filter Nama (Search)
+	if f.Viewer.Editor {
+		visible += fmt.Sprintf(" OR f.visibility = $%d", argIdx)
+		args = append(args, domain.FoodVisibilitySubmitted)
+		argIdx++
+	}
+
+	fmt.Fprintf(&where, " AND (%s)", visible)
+
+	if f.Visibility != "" {
+		fmt.Fprintf(&where, " AND f.visibility = $%d", argIdx)
+		args = append(args, f.Visibility)
+		argIdx++
+	}
+
+	// 1. Filter Nama & Deskripsi (Search)
+	// Full-text dengan prefix match untuk type-ahead, ditambah trigram pada nama untuk typo
+	rank := ""
 	if f.Query != "" {
-		fmt.Fprintf(&query, " AND f.name ILIKE $%d", argIdx)
-		args = append(args, "%"+f.Query+"%")
+		queryIdx := argIdx
+		args = append(args, f.Query)
 		argIdx++
+
+		rank = fmt.Sprintf("word_similarity($%d, f.name)", queryIdx)
+		match := fmt.Sprintf("$%d <%% f.name", queryIdx)
+		nameRank := fmt.Sprintf("word_similarity($%d, fnm.name)", queryIdx)
+		nameMatch := fmt.Sprintf("$%d <%% fnm.name", queryIdx)
+
+		if tsQuery := prefixTSQuery(f.Query); tsQuery != "" {
+			match = fmt.Sprintf("f.search_vector @@ to_tsquery('english', $%d) OR %s", argIdx, match)
+			rank = fmt.Sprintf("ts_rank(f.search_vector, to_tsquery('english', $%d)) + %s", argIdx, rank)
+			// Nama terjemahan pakai config 'simple' karena stemming english tidak cocok untuk bahasa lain
+			nameMatch = fmt.Sprintf("fnm.search_vector @@ to_tsquery('simple', $%d) OR %s", argIdx, nameMatch)
+			nameRank = fmt.Sprintf("ts_rank(fnm.search_vector, to_tsquery('simple', $%d)) + %s", argIdx, nameRank)
+			args = append(args, tsQuery)
+			argIdx++
+		}
+
+		// Nama per bahasa dan alias ikut dicari, rank diambil yang terbaik
+		fmt.Fprintf(&where, " AND (%s OR EXISTS (SELECT 1 FROM food_names fnm WHERE fnm.food_id = f.id AND (%s)))", match, nameMatch)
+		rank = fmt.Sprintf("GREATEST(%s, COALESCE((SELECT MAX(%s) FROM food_names fnm WHERE fnm.food_id = f.id), 0))", rank, nameRank)
 	}
 
-	// 3. Filter Kalori (Butuh Subquery atau Join)
-	if f.MaxCalories > 0 {
-		query.WriteString(fmt.Sprintf(`
-            AND f.id IN (
-                SELECT fn.food_id FROM food_nutrients fn
-                JOIN nutrients n ON fn.nutrient_id = n.id
-                WHERE n.name = 'Caloric Value' AND fn.amount <= $%d
-            )`, argIdx))
-		args = append(args, f.MaxCalories)
+	// 2. Filter Nutrient (per slug, sesuai basis)
+	for _, r := range f.Nutrients {
+		value := nutrientValueSubquery(f.Basis, argIdx)
+		args = append(args, r.Slug)
 		argIdx++
+
+		if r.Min != nil {
+			fmt.Fprintf(&where, " AND %s >= $%d", value, argIdx)
+			args = append(args, *r.Min)
+			argIdx++
+		}
+
+		if r.Max != nil {
+			fmt.Fprintf(&where, " AND %s <= $%d", value, argIdx)
+			args = append(args, *r.Max)
+			argIdx++
+		}
 	}
 
-	if f.MinCalories > 0 {
-		query.WriteString(fmt.Sprintf(`
-            AND f.id IN (
-                SELECT fn.food_id FROM food_nutrients fn
-                JOIN nutrients n ON fn.nutrient_id = n.id
-                WHERE n.name = 'Caloric Value' AND fn.amount >= $%d
-            )`, argIdx))
-		args = append(args, f.MinCalories)
+	// Total dihitung sebelum argumen sort & cursor ditambahkan
+	var total *int64
+	if f.Page.WithTotal {
+		var count int64
+		queryCount := "SELECT COUNT(*) FROM foods f" + where.String()
+		if err := s.db.QueryRowContext(ctx, queryCount, args...).Scan(&count); err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	// 3. Sort
+	var keys []sortKey
+	switch {
+	case f.Sort.Field == domain.FoodSortRelevance && rank != "":
+		keys = []sortKey{{rank, true}, {"f.name", false}, {"f.id", false}}
+	case f.Sort.Field != "" && f.Sort.Field != domain.FoodSortName && f.Sort.Field != domain.FoodSortRelevance:
+		value := nutrientValueSubquery(f.Basis, argIdx)
+		args = append(args, f.Sort.Field)
 		argIdx++
+
+		if f.Sort.Per100kcal {
+			value = fmt.Sprintf("%s * 100 / NULLIF(%s, 0)", value, nutrientValueSubquery(f.Basis, argIdx))
+			args = append(args, domain.NutrientCalories)
+			argIdx++
+		}
+
+		// Food yang tidak punya data nutrient tersebut selalu di akhir. Pakai sentinel
+		// dan bukan NULLS LAST supaya nilainya tetap bisa dibandingkan di cursor.
+		sentinel := "'Infinity'"
+		if f.Sort.Desc {
+			sentinel = "'-Infinity'"
+		}
+		keys = []sortKey{{fmt.Sprintf("COALESCE((%s)::float8, %s)", value, sentinel), f.Sort.Desc}, {"f.name", false}, {"f.id", false}}
+	default:
+		keys = []sortKey{{"f.name", f.Sort.Desc}, {"f.id", f.Sort.Desc}}
 	}
 
-	// 4. Sort & Pagination
-	fmt.Fprintf(&query, " ORDER BY f.name ASC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
-	args = append(args, f.Limit, f.Offset)
+	ks, err := newKeyset(f.Page.Cursor, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	if cond, cursorArgs := ks.where(argIdx); cond != "" {
+		fmt.Fprintf(&where, " AND %s", cond)
+		args = append(args, cursorArgs...)
+		argIdx += len(cursorArgs)
+	}
+
+	// 4. Pagination, ambil satu baris lebih untuk tahu masih ada halaman berikutnya
+	query := fmt.Sprintf(`
+        SELECT f.id, f.name, f.description, f.serving_size, f.serving_unit, f.density, f.owner_id, f.visibility, f.revision, %s
+        FROM foods f
+        %s
+        ORDER BY %s
+        LIMIT $%d
+    `, ks.columns(), where.String(), ks.orderBy(), argIdx)
+	args = append(args, f.Page.Limit+1)
 
 	// Eksekusi
-	rows, err := s.db.QueryContext(ctx, query.String(), args...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var foods []*domain.Food
-	var foodIDs []int64
-	foodMap := make(map[int64]*domain.Food)
+	var rowKeys [][]string
 
 	for rows.Next() {
 		f := &domain.Food{Nutrients: []domain.NutrientAmount{}}
 		var description sql.NullString
-		if err := rows.Scan(&f.ID, &f.Name, &description, &f.ServingSize, &f.ServingUnit); err != nil {
+		key := make([]string, len(keys))
+
+		dest := []any{&f.ID, &f.Name, &description, &f.ServingSize, &f.ServingUnit, &f.Density, &f.OwnerID, &f.Visibility, &f.Revision}
+		for i := range key {
+			dest = append(dest, &key[i])
+		}
+
+		if err := rows.Scan(dest...); err != nil {
 			return nil, err
 		}
 		f.Description = description.String
 
 		foods = append(foods, f)
-		foodIDs = append(foodIDs, f.ID)
-		foodMap[f.ID] = f
+		rowKeys = append(rowKeys, key)
 	}
 
-	if len(foodIDs) == 0 {
-		return foods, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := keysetPage(ks, foods, rowKeys, f.Page.Limit)
+	page.Total = total
+
+	if len(page.Data) == 0 {
+		page.Data = []*domain.Food{}
+		return page, nil
+	}
+
+	if err := s.attachDetails(ctx, page.Data); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// attachDetails mengisi nutrient, barcode dan nama per bahasa untuk beberapa food sekaligus
+func (s *FoodStore) attachDetails(ctx context.Context, foods []*domain.Food) error {
+	foodIDs := make([]int64, 0, len(foods))
+	foodMap := make(map[int64]*domain.Food, len(foods))
+	for _, f := range foods {
+		foodIDs = append(foodIDs, f.ID)
+		foodMap[f.ID] = f
 	}
 
 	queryNutrient := `
-	SELECT fn.food_id, n.id, n.name, n.unit, fn.amount
+	SELECT fn.food_id, n.id, n.name, n.slug, n.unit, fn.amount
 	FROM food_nutrients fn
 	JOIN nutrients n ON fn.nutrient_id = n.id
 	WHERE fn.food_id = ANY($1)
@@ -98,15 +223,15 @@ func (s *FoodStore) Search(ctx context.Context, f domain.FoodFilter) ([]*domain.
 
 	nutRows, err := s.db.QueryContext(ctx, queryNutrient, pq.Array(foodIDs))
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer nutRows.Close()
 
 	for nutRows.Next() {
 		var foodID int64
 		var na domain.NutrientAmount
-		if err := nutRows.Scan(&foodID, &na.ID, &na.Name, &na.Unit, &na.Amount); err != nil {
-			return nil, err
+		if err := nutRows.Scan(&foodID, &na.ID, &na.Name, &na.Slug, &na.Unit, &na.Amount); err != nil {
+			return err
 		}
 
 		if f, ok := foodMap[foodID]; ok {
@@ -114,14 +239,373 @@ func (s *FoodStore) Search(ctx context.Context, f domain.FoodFilter) ([]*domain.
 		}
 	}
 
+	if err := nutRows.Err(); err != nil {
+		return err
+	}
+
+	barcodes, err := s.barcodesByFood(ctx, foodIDs)
+	if err != nil {
+		return err
+	}
+
+	names, err := s.namesByFood(ctx, foodIDs)
+	if err != nil {
+		return err
+	}
+
+	portions, err := s.portionsByFood(ctx, foodIDs)
+	if err != nil {
+		return err
+	}
+
+	for id, f := range foodMap {
+		f.Barcodes = barcodes[id]
+		f.Names = names[id]
+		f.Portions = portions[id]
+	}
+
+	return nil
+}
+
+// ListByOwner mengambil semua food buatan user (termasuk yang sudah publik), dipakai untuk export data
+func (s *FoodStore) ListByOwner(ctx context.Context, ownerID int64) ([]*domain.Food, error) {
+	query := `
+	SELECT id, name, description, serving_size, serving_unit, density, owner_id, visibility, revision, created_at, updated_at
+	FROM foods
+	WHERE owner_id = $1 AND deleted_at IS NULL
+	ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	foods := []*domain.Food{}
+	for rows.Next() {
+		f := &domain.Food{Nutrients: []domain.NutrientAmount{}}
+		var description sql.NullString
+		if err := rows.Scan(
+			&f.ID, &f.Name, &description, &f.ServingSize, &f.ServingUnit, &f.Density,
+			&f.OwnerID, &f.Visibility, &f.Revision, &f.CreatedAt, &f.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		f.Description = description.String
+
+		foods = append(foods, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(foods) == 0 {
+		return foods, nil
+	}
+
+	if err := s.attachDetails(ctx, foods); err != nil {
+		return nil, err
+	}
+
 	return foods, nil
 }
 
+// SetVisibility memindahkan food dari satu visibility ke visibility lain,
+// ErrNotFound jika food tidak ada atau visibility-nya sudah berubah
+func (s *FoodStore) SetVisibility(ctx context.Context, id int64, from, to string) error {
+	query := `
+	UPDATE foods
+	SET visibility = $3, updated_at = NOW()
+	WHERE id = $1 AND visibility = $2 AND deleted_at IS NULL
+	`
+
+	res, err := s.db.ExecContext(ctx, query, id, from, to)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// barcodesByFood mengambil barcode untuk beberapa food sekaligus
+func (s *FoodStore) barcodesByFood(ctx context.Context, foodIDs []int64) (map[int64][]string, error) {
+	query := `
+	SELECT food_id, gtin
+	FROM food_barcodes
+	WHERE food_id = ANY($1)
+	ORDER BY created_at, gtin
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(foodIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	barcodes := make(map[int64][]string, len(foodIDs))
+	for _, id := range foodIDs {
+		barcodes[id] = []string{}
+	}
+
+	for rows.Next() {
+		var foodID int64
+		var gtin string
+		if err := rows.Scan(&foodID, &gtin); err != nil {
+			return nil, err
+		}
+		barcodes[foodID] = append(barcodes[foodID], gtin)
+	}
+
+	return barcodes, rows.Err()
+}
+
+// replaceBarcodes mengganti seluruh barcode food di dalam transaksi
+func replaceBarcodes(ctx context.Context, tx *sql.Tx, foodID int64, barcodes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM food_barcodes WHERE food_id = $1`, foodID); err != nil {
+		return err
+	}
+
+	for _, gtin := range barcodes {
+		query := `INSERT INTO food_barcodes (gtin, food_id) VALUES ($1, $2)`
+		if _, err := tx.ExecContext(ctx, query, gtin, foodID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// namesByFood mengambil nama per bahasa dan alias untuk beberapa food sekaligus
+func (s *FoodStore) namesByFood(ctx context.Context, foodIDs []int64) (map[int64][]domain.FoodName, error) {
+	query := `
+	SELECT food_id, locale, name, is_alias
+	FROM food_names
+	WHERE food_id = ANY($1)
+	ORDER BY locale, is_alias, name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(foodIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[int64][]domain.FoodName, len(foodIDs))
+	for _, id := range foodIDs {
+		names[id] = []domain.FoodName{}
+	}
+
+	for rows.Next() {
+		var foodID int64
+		var n domain.FoodName
+		if err := rows.Scan(&foodID, &n.Locale, &n.Name, &n.Alias); err != nil {
+			return nil, err
+		}
+		names[foodID] = append(names[foodID], n)
+	}
+
+	return names, rows.Err()
+}
+
+// replaceNames mengganti seluruh nama dan alias food di dalam transaksi
+func replaceNames(ctx context.Context, tx *sql.Tx, foodID int64, names []domain.FoodName) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM food_names WHERE food_id = $1`, foodID); err != nil {
+		return err
+	}
+
+	for _, n := range names {
+		query := `INSERT INTO food_names (food_id, locale, name, is_alias) VALUES ($1, $2, $3, $4)`
+		if _, err := tx.ExecContext(ctx, query, foodID, n.Locale, n.Name, n.Alias); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// portionsByFood mengambil takaran rumah tangga untuk beberapa food sekaligus
+func (s *FoodStore) portionsByFood(ctx context.Context, foodIDs []int64) (map[int64][]domain.FoodPortion, error) {
+	query := `
+	SELECT food_id, id, name, grams
+	FROM food_portions
+	WHERE food_id = ANY($1)
+	ORDER BY grams, name
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(foodIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	portions := make(map[int64][]domain.FoodPortion, len(foodIDs))
+	for _, id := range foodIDs {
+		portions[id] = []domain.FoodPortion{}
+	}
+
+	for rows.Next() {
+		var foodID int64
+		var p domain.FoodPortion
+		if err := rows.Scan(&foodID, &p.ID, &p.Name, &p.Grams); err != nil {
+			return nil, err
+		}
+		portions[foodID] = append(portions[foodID], p)
+	}
+
+	return portions, rows.Err()
+}
+
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func insertPortion(ctx context.Context, db rowQuerier, foodID int64, portion *domain.FoodPortion) error {
+	query := `INSERT INTO food_portions (food_id, name, grams) VALUES ($1, $2, $3) RETURNING id`
+
+	return db.QueryRowContext(ctx, query, foodID, portion.Name, portion.Grams).Scan(&portion.ID)
+}
+
+func (s *FoodStore) CreatePortion(ctx context.Context, foodID int64, portion *domain.FoodPortion) error {
+	return insertPortion(ctx, s.db, foodID, portion)
+}
+
+func (s *FoodStore) GetPortion(ctx context.Context, foodID, portionID int64) (*domain.FoodPortion, error) {
+	query := `SELECT id, name, grams FROM food_portions WHERE id = $1 AND food_id = $2`
+
+	portion := &domain.FoodPortion{}
+	if err := s.db.QueryRowContext(ctx, query, portionID, foodID).Scan(&portion.ID, &portion.Name, &portion.Grams); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return portion, nil
+}
+
+// DeletePortion menghapus portion, diary yang memakainya kembali ke amount_consumed (ON DELETE SET NULL)
+func (s *FoodStore) DeletePortion(ctx context.Context, foodID, portionID int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM food_portions WHERE id = $1 AND food_id = $2`, portionID, foodID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// nutrientValueSubquery mengembalikan jumlah satu nutrient (slug di parameter slugIdx) untuk food f.
+// Jumlah disimpan per serving, jadi untuk basis 100g dinormalisasi dengan berat serving
+// dalam gram. Serving berupa porsi, atau volume tanpa density, tidak punya nilai per 100g.
+func nutrientValueSubquery(basis string, slugIdx int) string {
+	value := "fn.amount"
+	if basis != domain.NutrientBasisServing {
+		value = fmt.Sprintf("fn.amount * 100 / NULLIF(f.serving_size * %s, 0)", gramsFactor("f.serving_unit", "f.density"))
+	}
+
+	return fmt.Sprintf(`(
+                SELECT %s FROM food_nutrients fn
+                JOIN nutrients n ON fn.nutrient_id = n.id
+                WHERE fn.food_id = f.id AND n.slug = $%d
+            )`, value, slugIdx)
+}
+
+// gramsFactor adalah padanan SQL converter.ToGrams: faktor pengali satuan di kolom unitColumn
+// ke gram. Satuan volume dikalikan density (g/ml), hasilnya NULL untuk satuan yang tidak bisa
+// diubah ke gram.
+func gramsFactor(unitColumn, densityColumn string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CASE %s", unitColumn)
+
+	for _, u := range converter.Units() {
+		factor := strconv.FormatFloat(u.Factor, 'f', -1, 64)
+		if u.Dimension == converter.Volume {
+			factor = fmt.Sprintf("%s * %s", factor, densityColumn)
+		}
+		fmt.Fprintf(&b, " WHEN '%s' THEN %s", u.Symbol, factor)
+	}
+
+	b.WriteString(" END")
+	return b.String()
+}
+
+// unitDimension menghasilkan dimensi (mass atau volume) satuan di kolom unitColumn, NULL
+// untuk satuan yang tidak dikenal converter
+func unitDimension(unitColumn string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CASE %s", unitColumn)
+
+	for _, u := range converter.Units() {
+		fmt.Fprintf(&b, " WHEN '%s' THEN '%s'", u.Symbol, u.Dimension)
+	}
+
+	b.WriteString(" END")
+	return b.String()
+}
+
+// prefixTSQuery mengubah input user menjadi tsquery "kata1:* & kata2:*".
+// Karakter selain huruf dan angka dibuang supaya input tidak bisa merusak sintaks tsquery.
+func prefixTSQuery(q string) string {
+	// Apostrof dibuang supaya "chicken's" tetap satu kata
+	q = strings.NewReplacer("'", "", "’", "").Replace(q)
+
+	words := strings.FieldsFunc(q, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, word+":*")
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+func (s *FoodStore) ListNutrients(ctx context.Context) ([]*domain.Nutrient, error) {
+	query := `SELECT id, name, slug, unit FROM nutrients ORDER BY name`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nutrients := []*domain.Nutrient{}
+	for rows.Next() {
+		n := &domain.Nutrient{}
+		if err := rows.Scan(&n.ID, &n.Name, &n.Slug, &n.Unit); err != nil {
+			return nil, err
+		}
+		nutrients = append(nutrients, n)
+	}
+
+	return nutrients, rows.Err()
+}
+
 func (s *FoodStore) GetPaginated(ctx context.Context, limit, offset int) ([]*domain.Food, error) {
 	queryFoods := `
-	SELECT id, name, description, serving_size, serving_unit
+	SELECT id, name, description, serving_size, serving_unit, density
 	FROM foods
-	WHERE deleted_at IS NULL
+	WHERE deleted_at IS NULL AND visibility = 'public'
 	LIMIT $1 OFFSET $2
 	`
 
@@ -138,7 +622,7 @@ func (s *FoodStore) GetPaginated(ctx context.Context, limit, offset int) ([]*dom
 	for rows.Next() {
 		f := &domain.Food{Nutrients: []domain.NutrientAmount{}}
 		var description sql.NullString
-		if err := rows.Scan(&f.ID, &f.Name, &description, &f.ServingSize, &f.ServingUnit); err != nil {
+		if err := rows.Scan(&f.ID, &f.Name, &description, &f.ServingSize, &f.ServingUnit, &f.Density); err != nil {
 			return nil, err
 		}
 		f.Description = description.String
@@ -153,7 +637,7 @@ func (s *FoodStore) GetPaginated(ctx context.Context, limit, offset int) ([]*dom
 	}
 
 	queryNutrient := `
-	SELECT fn.food_id, n.id, n.name, n.unit, fn.amount
+	SELECT fn.food_id, n.id, n.name, n.slug, n.unit, fn.amount
 	FROM food_nutrients fn
 	JOIN nutrients n ON fn.nutrient_id = n.id
 	WHERE fn.food_id = ANY($1)
@@ -168,7 +652,7 @@ func (s *FoodStore) GetPaginated(ctx context.Context, limit, offset int) ([]*dom
 	for nutRows.Next() {
 		var foodID int64
 		var na domain.NutrientAmount
-		if err := nutRows.Scan(&foodID, &na.ID, &na.Name, &na.Unit, &na.Amount); err != nil {
+		if err := nutRows.Scan(&foodID, &na.ID, &na.Name, &na.Slug, &na.Unit, &na.Amount); err != nil {
 			return nil, err
 		}
 
@@ -187,15 +671,21 @@ func (s *FoodStore) GetByID(ctx context.Context, id int64) (*domain.Food, error)
 							 f.description,
 							 f.serving_size,
 							 f.serving_unit,
+							 f.density,
+							 f.owner_id,
+							 f.visibility,
+							 f.revision,
+							 f.external_id,
 							 fn.amount,
 							 n.id,
 							 n.name AS nutrient_name,
+							 n.slug,
 							 n.unit,
 							 f.created_at,
 							 f.updated_at
         FROM foods f
-				JOIN food_nutrients fn ON fn.food_id = f.id
-				JOIN nutrients n ON n.id = fn.nutrient_id
+				LEFT JOIN food_nutrients fn ON fn.food_id = f.id
+				LEFT JOIN nutrients n ON n.id = fn.nutrient_id
         WHERE f.id = $1
 					AND deleted_at IS NULL
     `
@@ -210,25 +700,25 @@ func (s *FoodStore) GetByID(ctx context.Context, id int64) (*domain.Food, error)
 
 	for rows.Next() {
 		var nID sql.NullInt64
-		var nName, nUnit, nDescription sql.NullString
+		var nName, nSlug, nUnit, nDescription sql.NullString
 		var nAmount sql.NullFloat64
 
 		if food == nil {
 			food = &domain.Food{Nutrients: []domain.NutrientAmount{}}
 			err = rows.Scan(
-				&food.ID, &food.Name, &nDescription, &food.ServingSize, &food.ServingUnit,
-				&nAmount, &nID, &nName, &nUnit, &food.CreatedAt, &food.UpdatedAt,
+				&food.ID, &food.Name, &nDescription, &food.ServingSize, &food.ServingUnit, &food.Density,
+				&food.OwnerID, &food.Visibility, &food.Revision, &food.ExternalID, &nAmount, &nID, &nName, &nSlug, &nUnit, &food.CreatedAt, &food.UpdatedAt,
 			)
 
 			food.Description = nDescription.String
 		} else {
-			var ignoreID sql.NullInt64
-			var ignoreName, ignoreUnit, ignoreDescription sql.NullString
-			var ignoreSize sql.NullFloat64
+			var ignoreID, ignoreOwnerID, ignoreRevision sql.NullInt64
+			var ignoreName, ignoreUnit, ignoreDescription, ignoreVisibility, ignoreExternalID sql.NullString
+			var ignoreSize, ignoreDensity sql.NullFloat64
 			var ignoreCreatedAt, ignoreUpdatedAt time.Time
 			err = rows.Scan(
-				&ignoreID, &ignoreName, &ignoreDescription, &ignoreSize, &ignoreUnit,
-				&nAmount, &nID, &nName, &nUnit,
+				&ignoreID, &ignoreName, &ignoreDescription, &ignoreSize, &ignoreUnit, &ignoreDensity,
+				&ignoreOwnerID, &ignoreVisibility, &ignoreRevision, &ignoreExternalID, &nAmount, &nID, &nName, &nSlug, &nUnit,
 				&ignoreCreatedAt, &ignoreUpdatedAt,
 			)
 		}
@@ -241,6 +731,7 @@ func (s *FoodStore) GetByID(ctx context.Context, id int64) (*domain.Food, error)
 			food.Nutrients = append(food.Nutrients, domain.NutrientAmount{
 				ID:     nID.Int64,
 				Name:   nName.String,
+				Slug:   nSlug.String,
 				Unit:   nUnit.String,
 				Amount: nAmount.Float64,
 			})
@@ -251,9 +742,46 @@ func (s *FoodStore) GetByID(ctx context.Context, id int64) (*domain.Food, error)
 		return nil, ErrNotFound
 	}
 
+	barcodes, err := s.barcodesByFood(ctx, []int64{food.ID})
+	if err != nil {
+		return nil, err
+	}
+	food.Barcodes = barcodes[food.ID]
+
+	names, err := s.namesByFood(ctx, []int64{food.ID})
+	if err != nil {
+		return nil, err
+	}
+	food.Names = names[food.ID]
+
+	portions, err := s.portionsByFood(ctx, []int64{food.ID})
+	if err != nil {
+		return nil, err
+	}
+	food.Portions = portions[food.ID]
+
 	return food, nil
 }
 
+func (s *FoodStore) GetByBarcode(ctx context.Context, gtin string) (*domain.Food, error) {
+	query := `
+	SELECT b.food_id
+	FROM food_barcodes b
+	JOIN foods f ON f.id = b.food_id AND f.deleted_at IS NULL
+	WHERE b.gtin = $1
+	`
+
+	var foodID int64
+	if err := s.db.QueryRowContext(ctx, query, gtin).Scan(&foodID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return s.GetByID(ctx, foodID)
+}
+
 func (s *FoodStore) Create(ctx context.Context, food *domain.Food) error {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -262,17 +790,30 @@ func (s *FoodStore) Create(ctx context.Context, food *domain.Food) error {
 
 	defer tx.Rollback()
 
+	if err := insertFood(ctx, tx, food); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertFood menyimpan food beserta nutrient, barcode dan namanya di dalam transaksi
+func insertFood(ctx context.Context, tx *sql.Tx, food *domain.Food) error {
 	queryFood := `
-			INSERT INTO foods (name, description, serving_size, serving_unit)
-			VALUES ($1, $2, $3, $4)
+			INSERT INTO foods (name, description, serving_size, serving_unit, density, owner_id, visibility, external_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 			RETURNING id, created_at, updated_at
 	`
 
-	err = tx.QueryRowContext(ctx, queryFood,
+	err := tx.QueryRowContext(ctx, queryFood,
 		food.Name,
 		food.Description,
 		food.ServingSize,
 		food.ServingUnit,
+		food.Density,
+		food.OwnerID,
+		food.Visibility,
+		food.ExternalID,
 	).Scan(&food.ID, &food.CreatedAt, &food.UpdatedAt)
 
 	if err != nil {
@@ -298,7 +839,21 @@ func (s *FoodStore) Create(ctx context.Context, food *domain.Food) error {
 		}
 	}
 
-	return tx.Commit()
+	if err := replaceBarcodes(ctx, tx, food.ID, food.Barcodes); err != nil {
+		return err
+	}
+
+	if err := replaceNames(ctx, tx, food.ID, food.Names); err != nil {
+		return err
+	}
+
+	for i := range food.Portions {
+		if err := insertPortion(ctx, tx, food.ID, &food.Portions[i]); err != nil {
+			return err
+		}
+	}
+
+	return snapshotRevision(ctx, tx, food)
 }
 
 func (s *FoodStore) Update(ctx context.Context, food *domain.Food) error {
@@ -309,18 +864,32 @@ func (s *FoodStore) Update(ctx context.Context, food *domain.Food) error {
 	}
 	defer tx.Rollback()
 
+	if err := updateFood(ctx, tx, food); err != nil {
+		return err
+	}
+
+	// 5. Selesaikan Transaksi
+	return tx.Commit()
+}
+
+// updateFood memperbarui food di dalam transaksi. Nutrient, barcode dan nama hanya
+// diganti jika tidak nil.
+func updateFood(ctx context.Context, tx *sql.Tx, food *domain.Food) error {
 	// 2. Update data utama makanan
 	queryFood := `
 		UPDATE foods
-		SET name = $1, description = $2, serving_size = $3, serving_unit = $4, updated_at = NOW()
-		WHERE id = $5 AND deleted_at IS NULL`
+		SET name = $1, description = $2, serving_size = $3, serving_unit = $4, density = $5,
+			external_id = COALESCE($7, external_id), updated_at = NOW()
+		WHERE id = $6 AND deleted_at IS NULL`
 
 	res, err := tx.ExecContext(ctx, queryFood,
 		food.Name,
 		food.Description,
 		food.ServingSize,
 		food.ServingUnit,
+		food.Density,
 		food.ID,
+		food.ExternalID,
 	)
 	if err != nil {
 		return err
@@ -359,18 +928,35 @@ func (s *FoodStore) Update(ctx context.Context, food *domain.Food) error {
 		}
 	}
 
-	// 5. Selesaikan Transaksi
-	return tx.Commit()
+	if food.Barcodes != nil {
+		if err := replaceBarcodes(ctx, tx, food.ID, food.Barcodes); err != nil {
+			return err
+		}
+	}
+
+	if food.Names != nil {
+		if err := replaceNames(ctx, tx, food.ID, food.Names); err != nil {
+			return err
+		}
+	}
+
+	return snapshotRevision(ctx, tx, food)
 }
 
 func (s *FoodStore) Delete(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 	UPDATE foods
 	SET deleted_at = NOW()
 	WHERE id = $1
 	`
 
-	res, err := s.db.ExecContext(ctx, query, id)
+	res, err := tx.ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -384,5 +970,10 @@ func (s *FoodStore) Delete(ctx context.Context, id int64) error {
 		return ErrNotFound
 	}
 
-	return nil
+	// Barcode dilepas supaya bisa dipakai food lain
+	if _, err := tx.ExecContext(ctx, `DELETE FROM food_barcodes WHERE food_id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
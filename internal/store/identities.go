@@ -0,0 +1,188 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+type IdentityStore struct {
+	db *sql.DB
+}
+
+func (s *IdentityStore) GetByProviderSubject(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	query := `
+	SELECT i.id, i.user_id, i.provider, i.subject, i.email, i.created_at, i.last_login_at
+	FROM user_identities i
+	JOIN users u ON u.id = i.user_id AND u.deleted_at IS NULL
+	WHERE i.provider = $1 AND i.subject = $2
+	`
+
+	identity := &domain.UserIdentity{}
+	var email sql.NullString
+	err := s.db.QueryRowContext(ctx, query, provider, subject).Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Provider,
+		&identity.Subject,
+		&email,
+		&identity.CreatedAt,
+		&identity.LastLoginAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	identity.Email = email.String
+
+	return identity, nil
+}
+
+func (s *IdentityStore) ListByUser(ctx context.Context, userID int64) ([]*domain.UserIdentity, error) {
+	query := `
+	SELECT id, user_id, provider, subject, COALESCE(email, ''), created_at, last_login_at
+	FROM user_identities
+	WHERE user_id = $1
+	ORDER BY created_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []*domain.UserIdentity{}
+	for rows.Next() {
+		identity := &domain.UserIdentity{}
+		if err := rows.Scan(
+			&identity.ID,
+			&identity.UserID,
+			&identity.Provider,
+			&identity.Subject,
+			&identity.Email,
+			&identity.CreatedAt,
+			&identity.LastLoginAt,
+		); err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
+
+func (s *IdentityStore) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	return createIdentity(ctx, s.db, identity)
+}
+
+// CreateWithUser mendaftarkan user baru dari login eksternal beserta identity-nya dalam satu transaksi.
+// Email sudah diverifikasi oleh provider.
+func (s *IdentityStore) CreateWithUser(ctx context.Context, user *domain.User, identity *domain.UserIdentity) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+	INSERT INTO users (username, password, email, email_verified_at)
+	VALUES ($1, $2, $3, NOW())
+	RETURNING id, role, email_verified_at, totp_enabled_at, created_at, updated_at
+	`
+
+	err = tx.QueryRowContext(ctx, query, user.Username, user.Password, user.Email).Scan(
+		&user.ID,
+		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.TOTPEnabledAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	identity.UserID = user.ID
+	if err := createIdentity(ctx, tx, identity); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *IdentityStore) TouchLogin(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE user_identities SET last_login_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+func (s *IdentityStore) CreateAuthRequest(ctx context.Context, req *domain.OIDCAuthRequest) error {
+	// Bersihkan request lama yang tidak pernah diselesaikan
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM oidc_auth_requests WHERE expires_at <= NOW()`); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO oidc_auth_requests (state_hash, provider, code_verifier, nonce, expires_at)
+	VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := s.db.ExecContext(ctx, query, req.StateHash, req.Provider, req.CodeVerifier, req.Nonce, req.ExpiresAt)
+	return err
+}
+
+// ConsumeAuthRequest mengambil dan menghapus request sekaligus supaya state hanya bisa dipakai sekali
+func (s *IdentityStore) ConsumeAuthRequest(ctx context.Context, stateHash []byte) (*domain.OIDCAuthRequest, error) {
+	query := `
+	DELETE FROM oidc_auth_requests
+		WHERE state_hash = $1 AND expires_at > NOW()
+		RETURNING state_hash, provider, code_verifier, nonce, expires_at
+	`
+
+	req := &domain.OIDCAuthRequest{}
+	err := s.db.QueryRowContext(ctx, query, stateHash).Scan(
+		&req.StateHash,
+		&req.Provider,
+		&req.CodeVerifier,
+		&req.Nonce,
+		&req.ExpiresAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return req, nil
+}
+
+type queryRower interface {
+	QueryRowContext(context.Context, string, ...any) *sql.Row
+}
+
+func createIdentity(ctx context.Context, db queryRower, identity *domain.UserIdentity) error {
+	query := `
+	INSERT INTO user_identities (user_id, provider, subject, email, last_login_at)
+	VALUES ($1, $2, $3, NULLIF($4, ''), NOW())
+	RETURNING id, created_at, last_login_at
+	`
+
+	return db.QueryRowContext(ctx, query,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+	).Scan(
+		&identity.ID,
+		&identity.CreatedAt,
+		&identity.LastLoginAt,
+	)
+}
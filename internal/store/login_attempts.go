@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type LoginAttemptStore struct {
+	db *sql.DB
+}
+
+// LockedUntil mengembalikan waktu akhir lockout, atau nil jika key tidak sedang terkunci
+func (s *LoginAttemptStore) LockedUntil(ctx context.Context, key string) (*time.Time, error) {
+	query := `
+	SELECT locked_until
+	FROM login_attempts
+	WHERE key = $1 AND locked_until > NOW()
+	`
+
+	var lockedUntil time.Time
+	err := s.db.QueryRowContext(ctx, query, key).Scan(&lockedUntil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &lockedUntil, nil
+}
+
+// RecordFailure menambah counter gagal dan mengembalikan jumlah kegagalan terbaru.
+// Counter mulai dari awal lagi jika kegagalan terakhir sudah lewat dari window.
+func (s *LoginAttemptStore) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	query := `
+	INSERT INTO login_attempts (key, failures, last_failed_at)
+	VALUES ($1, 1, NOW())
+	ON CONFLICT (key) DO UPDATE
+		SET failures = CASE
+				WHEN login_attempts.last_failed_at < NOW() - make_interval(secs => $2) THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failed_at = NOW()
+	RETURNING failures
+	`
+
+	var failures int
+	if err := s.db.QueryRowContext(ctx, query, key, window.Seconds()).Scan(&failures); err != nil {
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+func (s *LoginAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	query := `
+	UPDATE login_attempts
+		SET locked_until = $2
+		WHERE key = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, key, until)
+	return err
+}
+
+func (s *LoginAttemptStore) Reset(ctx context.Context, key string) error {
+	query := `DELETE FROM login_attempts WHERE key = $1`
+
+	_, err := s.db.ExecContext(ctx, query, key)
+	return err
+}
@@ -2,7 +2,11 @@ package store
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"net/url"
+	"path"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 )
@@ -28,3 +32,41 @@ func (m *MinioStore) Upload(ctx context.Context, fileName string, content io.Rea
 	}
 	return fileName, nil
 }
+
+func (m *MinioStore) Download(ctx context.Context, fileName string) (io.ReadCloser, error) {
+	return m.client.GetObject(ctx, m.bucketName, fileName, minio.GetObjectOptions{})
+}
+
+func (m *MinioStore) Delete(ctx context.Context, fileName string) error {
+	return m.client.RemoveObject(ctx, m.bucketName, fileName, minio.RemoveObjectOptions{})
+}
+
+func (m *MinioStore) DeletePrefix(ctx context.Context, prefix string) error {
+	objects := m.client.ListObjects(ctx, m.bucketName, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	})
+
+	for object := range objects {
+		if object.Err != nil {
+			return object.Err
+		}
+
+		if err := m.client.RemoveObject(ctx, m.bucketName, object.Key, minio.RemoveObjectOptions{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *MinioStore) PresignedURL(ctx context.Context, fileName string, expiry time.Duration) (string, error) {
+	params := url.Values{}
+	params.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", path.Base(fileName)))
+
+	u, err := m.client.PresignedGetObject(ctx, m.bucketName, fileName, expiry, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+type OutboxStore struct {
+	db *sql.DB
+}
+
+func (s *OutboxStore) Enqueue(ctx context.Context, email domain.Email) error {
+	query := `
+	INSERT INTO email_outbox (recipient, subject, body)
+	VALUES ($1, $2, $3)
+	`
+
+	_, err := s.db.ExecContext(ctx, query, email.To, email.Subject, email.Body)
+	return err
+}
+
+// Claim mengambil email yang siap dikirim dan memundurkan send_after selama
+// lease, sehingga beberapa instance API tidak mengirim email yang sama.
+func (s *OutboxStore) Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxEmail, error) {
+	query := `
+	UPDATE email_outbox
+		SET send_after = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE sent_at IS NULL AND failed_at IS NULL AND send_after <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, recipient, subject, body, attempts, created_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var emails []*domain.OutboxEmail
+
+	for rows.Next() {
+		e := &domain.OutboxEmail{}
+		if err := rows.Scan(
+			&e.ID,
+			&e.Email.To,
+			&e.Email.Subject,
+			&e.Email.Body,
+			&e.Attempts,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		emails = append(emails, e)
+	}
+
+	return emails, rows.Err()
+}
+
+func (s *OutboxStore) MarkSent(ctx context.Context, id int64) error {
+	query := `
+	UPDATE email_outbox
+		SET sent_at = NOW(), attempts = attempts + 1, last_error = NULL
+		WHERE id = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (s *OutboxStore) MarkFailed(ctx context.Context, id int64, sendErr error, retryAt time.Time) error {
+	query := `
+	UPDATE email_outbox
+		SET attempts = attempts + 1, last_error = $2, send_after = $3
+		WHERE id = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id, sendErr.Error(), retryAt)
+	return err
+}
+
+// MarkDead menghentikan pengiriman ulang email yang sudah melewati batas percobaan
+func (s *OutboxStore) MarkDead(ctx context.Context, id int64, sendErr error) error {
+	query := `
+	UPDATE email_outbox
+		SET attempts = attempts + 1, last_error = $2, failed_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id, sendErr.Error())
+	return err
+}
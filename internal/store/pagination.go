@@ -0,0 +1,118 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+// sortKey adalah satu kolom urutan untuk paginasi keyset
+type sortKey struct {
+	expr string
+	desc bool
+}
+
+// keyset membangun bagian query untuk paginasi keyset. Kombinasi semua key harus
+// unik, jadi key terakhir biasanya id.
+type keyset struct {
+	keys   []sortKey
+	cursor *domain.Cursor
+}
+
+func newKeyset(cursor *domain.Cursor, keys ...sortKey) (*keyset, error) {
+	// Cursor dari urutan lain (misal sort diganti) tidak bisa dipakai
+	if cursor != nil && len(cursor.Keys) != len(keys) {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	return &keyset{keys: keys, cursor: cursor}, nil
+}
+
+func (k *keyset) backward() bool {
+	return k.cursor != nil && k.cursor.Backward
+}
+
+// columns mengambil nilai key sebagai text supaya bisa disimpan apa adanya di cursor
+func (k *keyset) columns() string {
+	cols := make([]string, len(k.keys))
+	for i, key := range k.keys {
+		cols[i] = fmt.Sprintf("(%s)::text", key.expr)
+	}
+
+	return strings.Join(cols, ", ")
+}
+
+// where membuat kondisi baris setelah cursor (atau sebelum, untuk halaman sebelumnya):
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., operator dibalik untuk key descending
+func (k *keyset) where(argIdx int) (string, []any) {
+	if k.cursor == nil {
+		return "", nil
+	}
+
+	conds := make([]string, len(k.keys))
+	for i, key := range k.keys {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = $%d", k.keys[j].expr, argIdx+j))
+		}
+
+		op := ">"
+		if key.desc != k.backward() {
+			op = "<"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s $%d", key.expr, op, argIdx+i))
+
+		conds[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+
+	args := make([]any, len(k.cursor.Keys))
+	for i, value := range k.cursor.Keys {
+		args[i] = value
+	}
+
+	return "(" + strings.Join(conds, " OR ") + ")", args
+}
+
+func (k *keyset) orderBy() string {
+	parts := make([]string, len(k.keys))
+	for i, key := range k.keys {
+		direction := "ASC"
+		if key.desc != k.backward() {
+			direction = "DESC"
+		}
+		parts[i] = key.expr + " " + direction
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// keysetPage memotong hasil query (diambil limit+1 baris untuk tahu masih ada halaman
+// lain atau tidak) dan membuat cursor next/prev dari key item pertama dan terakhir
+func keysetPage[T any](k *keyset, items []T, keys [][]string, limit int) *domain.Page[T] {
+	hasMore := len(items) > limit
+	if hasMore {
+		items, keys = items[:limit], keys[:limit]
+	}
+
+	if k.backward() {
+		slices.Reverse(items)
+		slices.Reverse(keys)
+	}
+
+	page := &domain.Page[T]{Data: items}
+	if len(items) == 0 {
+		return page
+	}
+
+	if hasMore || k.backward() {
+		page.NextCursor = &domain.Cursor{Keys: keys[len(keys)-1]}
+	}
+
+	if k.cursor != nil && (hasMore || !k.backward()) {
+		page.PrevCursor = &domain.Cursor{Keys: keys[0], Backward: true}
+	}
+
+	return page
+}
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/lib/pq"
+)
+
+type RecipeStore struct {
+	db *sql.DB
+}
+
+// Create menyimpan food resep (dengan nutrient hasil hitungan) beserta yield dan bahannya
+func (s *RecipeStore) Create(ctx context.Context, recipe *domain.Recipe) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertFood(ctx, tx, recipe.Food); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO recipes (food_id, yield_servings, yield_weight) VALUES ($1, $2, $3)`
+	if _, err := tx.ExecContext(ctx, query, recipe.ID, recipe.YieldServings, recipe.YieldWeight); err != nil {
+		return err
+	}
+
+	if err := replaceIngredients(ctx, tx, recipe); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *RecipeStore) Update(ctx context.Context, recipe *domain.Recipe) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateFood(ctx, tx, recipe.Food); err != nil {
+		return err
+	}
+
+	query := `UPDATE recipes SET yield_servings = $2, yield_weight = $3 WHERE food_id = $1`
+	res, err := tx.ExecContext(ctx, query, recipe.ID, recipe.YieldServings, recipe.YieldWeight)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	if err := replaceIngredients(ctx, tx, recipe); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceIngredients(ctx context.Context, tx *sql.Tx, recipe *domain.Recipe) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recipe_ingredients WHERE recipe_id = $1`, recipe.ID); err != nil {
+		return err
+	}
+
+	for i, ing := range recipe.Ingredients {
+		query := `INSERT INTO recipe_ingredients (recipe_id, food_id, quantity, unit, position) VALUES ($1, $2, $3, $4, $5)`
+		if _, err := tx.ExecContext(ctx, query, recipe.ID, ing.FoodID, ing.Quantity, ing.Unit, i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetByFoodID mengambil yield dan bahan resep, Food tidak diisi (ambil lewat FoodStore).
+// ErrNotFound jika food tersebut bukan resep.
+func (s *RecipeStore) GetByFoodID(ctx context.Context, foodID int64) (*domain.Recipe, error) {
+	recipe := &domain.Recipe{Ingredients: []domain.RecipeIngredient{}}
+
+	query := `SELECT yield_servings, yield_weight FROM recipes WHERE food_id = $1`
+	if err := s.db.QueryRowContext(ctx, query, foodID).Scan(&recipe.YieldServings, &recipe.YieldWeight); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	queryIngredients := `
+	SELECT ri.food_id, f.name, ri.quantity, ri.unit
+	FROM recipe_ingredients ri
+	JOIN foods f ON f.id = ri.food_id
+	WHERE ri.recipe_id = $1
+	ORDER BY ri.position
+	`
+
+	rows, err := s.db.QueryContext(ctx, queryIngredients, foodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ing domain.RecipeIngredient
+		if err := rows.Scan(&ing.FoodID, &ing.FoodName, &ing.Quantity, &ing.Unit); err != nil {
+			return nil, err
+		}
+		recipe.Ingredients = append(recipe.Ingredients, ing)
+	}
+
+	return recipe, rows.Err()
+}
+
+// ListIDsByIngredient mengambil resep aktif yang memakai salah satu food sebagai bahan
+func (s *RecipeStore) ListIDsByIngredient(ctx context.Context, foodIDs []int64) ([]int64, error) {
+	query := `
+	SELECT DISTINCT ri.recipe_id
+	FROM recipe_ingredients ri
+	JOIN foods f ON f.id = ri.recipe_id AND f.deleted_at IS NULL
+	WHERE ri.food_id = ANY($1)
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(foodIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,274 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+type SessionStore struct {
+	db *sql.DB
+}
+
+func (s *SessionStore) Create(ctx context.Context, session *domain.Session) error {
+	query := `
+	INSERT INTO sessions (user_id, user_agent, ip_address, expires_at)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, created_at, last_seen_at
+	`
+
+	return s.db.QueryRowContext(ctx, query,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		session.ExpiresAt,
+	).Scan(
+		&session.ID,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+	)
+}
+
+func (s *SessionStore) GetByID(ctx context.Context, id int64) (*domain.Session, error) {
+	query := `
+	SELECT id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at
+	FROM sessions
+	WHERE id = $1
+	`
+
+	session := &domain.Session{}
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *SessionStore) ListActiveByUser(ctx context.Context, userID int64) ([]*domain.Session, error) {
+	query := `
+	SELECT id, user_id, user_agent, ip_address, created_at, last_seen_at, expires_at, revoked_at
+	FROM sessions
+	WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+	ORDER BY last_seen_at DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*domain.Session{}
+	for rows.Next() {
+		session := &domain.Session{}
+		if err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+			&session.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Touch memperbarui last_seen_at paling sering sekali per menit per session
+func (s *SessionStore) Touch(ctx context.Context, id int64) error {
+	query := `
+	UPDATE sessions
+		SET last_seen_at = NOW()
+		WHERE id = $1 AND last_seen_at < NOW() - INTERVAL '1 minute'
+	`
+
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (s *SessionStore) Revoke(ctx context.Context, id int64) error {
+	query := `
+	UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+
+	res, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *SessionStore) RevokeForUser(ctx context.Context, userID, id int64) error {
+	query := `
+	UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	res, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *SessionStore) RevokeAllForUser(ctx context.Context, userID int64) error {
+	query := `
+	UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`
+
+	_, err := s.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+func (s *SessionStore) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	query := `
+	INSERT INTO refresh_tokens (session_id, token_hash)
+	VALUES ($1, $2)
+	RETURNING id, created_at
+	`
+
+	return s.db.QueryRowContext(ctx, query, token.SessionID, token.TokenHash).Scan(
+		&token.ID,
+		&token.CreatedAt,
+	)
+}
+
+// ConsumeRefreshToken menandai refresh token sebagai terpakai secara atomik.
+// Token yang sudah pernah dipakai menghasilkan ErrTokenReused supaya pemanggil
+// bisa mencabut session-nya (indikasi token dicuri).
+func (s *SessionStore) ConsumeRefreshToken(ctx context.Context, tokenHash []byte) (*domain.RefreshToken, error) {
+	query := `
+	UPDATE refresh_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL
+		RETURNING id, session_id, created_at, used_at
+	`
+
+	token := &domain.RefreshToken{TokenHash: tokenHash}
+	err := s.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.SessionID,
+		&token.CreatedAt,
+		&token.UsedAt,
+	)
+
+	if err == nil {
+		return token, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// Bedakan token yang tidak dikenal dengan token yang dipakai ulang
+	queryUsed := `SELECT id, session_id FROM refresh_tokens WHERE token_hash = $1`
+	err = s.db.QueryRowContext(ctx, queryUsed, tokenHash).Scan(&token.ID, &token.SessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return token, domain.ErrTokenReused
+}
+
+func (s *SessionStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	query := `
+	INSERT INTO revoked_tokens (jti, expires_at)
+	VALUES ($1, $2)
+	ON CONFLICT (jti) DO NOTHING
+	`
+
+	_, err := s.db.ExecContext(ctx, query, jti, expiresAt)
+	return err
+}
+
+// ConsumeToken mencabut token sekali pakai secara atomik, false berarti token sudah pernah
+// dipakai (atau dicabut) oleh request lain
+func (s *SessionStore) ConsumeToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	query := `
+	INSERT INTO revoked_tokens (jti, expires_at)
+	VALUES ($1, $2)
+	ON CONFLICT (jti) DO NOTHING
+	`
+
+	res, err := s.db.ExecContext(ctx, query, jti, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// IsRevoked mengecek session dan jti access token dalam satu query
+// karena dipanggil di setiap request yang terautentikasi.
+func (s *SessionStore) IsRevoked(ctx context.Context, sessionID int64, jti string) (bool, error) {
+	query := `
+	SELECT
+		NOT EXISTS (
+			SELECT 1 FROM sessions
+			WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		)
+		OR EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $2)
+	`
+
+	var revoked bool
+	if err := s.db.QueryRowContext(ctx, query, sessionID, jti).Scan(&revoked); err != nil {
+		return false, err
+	}
+
+	return revoked, nil
+}
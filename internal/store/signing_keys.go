@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+// Lock id untuk pg_advisory_xact_lock supaya beberapa instance tidak merotasi key bersamaan
+const signingKeyRotationLock = 7301
+
+type SigningKeyStore struct {
+	db *sql.DB
+}
+
+func (s *SigningKeyStore) ListValid(ctx context.Context) ([]*domain.SigningKey, error) {
+	query := `
+	SELECT id, algorithm, private_key, public_key, activates_at, retires_at, expires_at, created_at
+	FROM signing_keys
+	WHERE expires_at > NOW()
+	ORDER BY activates_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*domain.SigningKey{}
+	for rows.Next() {
+		key := &domain.SigningKey{}
+		if err := rows.Scan(
+			&key.ID,
+			&key.Algorithm,
+			&key.PrivateKey,
+			&key.PublicKey,
+			&key.ActivatesAt,
+			&key.RetiresAt,
+			&key.ExpiresAt,
+			&key.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// CreateIfUncovered menyimpan key baru hanya jika belum ada key yang masih aktif
+// sampai horizon. Mengembalikan false jika instance lain sudah lebih dulu merotasi.
+func (s *SigningKeyStore) CreateIfUncovered(ctx context.Context, key *domain.SigningKey, horizon time.Time) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, signingKeyRotationLock); err != nil {
+		return false, err
+	}
+
+	var covered bool
+	queryCovered := `SELECT EXISTS (SELECT 1 FROM signing_keys WHERE retires_at > $1)`
+	if err := tx.QueryRowContext(ctx, queryCovered, horizon).Scan(&covered); err != nil {
+		return false, err
+	}
+
+	if covered {
+		return false, nil
+	}
+
+	query := `
+	INSERT INTO signing_keys (id, algorithm, private_key, public_key, activates_at, retires_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING created_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		key.ID,
+		key.Algorithm,
+		key.PrivateKey,
+		key.PublicKey,
+		key.ActivatesAt,
+		key.RetiresAt,
+		key.ExpiresAt,
+	).Scan(&key.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (s *SigningKeyStore) DeleteExpired(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM signing_keys WHERE expires_at <= NOW()`)
+	return err
+}
@@ -10,40 +10,178 @@ import (
 
 type Storage struct {
 	Users interface {
-		GetPaginated(context.Context, int, int) ([]*domain.User, error)
+		GetPaginated(context.Context, domain.PageRequest) (*domain.Page[*domain.User], error)
 		GetAll(context.Context) ([]domain.User, error)
 		GetByID(context.Context, int64) (*domain.User, error)
 		GetByEmail(context.Context, string) (*domain.User, error)
+		GetPasswordHash(context.Context, int64) (string, error)
+		GetAvatar(context.Context, int64) (*string, error)
 		Create(context.Context, *domain.User) error
 		Update(context.Context, *domain.User) error
 		UpdateAvatar(context.Context, int64, string) error
+		UpdatePassword(context.Context, int64, string) error
+		MarkEmailVerified(context.Context, int64) error
+		UpdateRole(context.Context, int64, domain.Role) error
 		Delete(context.Context, int64) error
+		ScheduleDeletion(context.Context, int64, time.Time) error
+		CancelDeletion(context.Context, int64) (bool, error)
+		ListDueForPurge(context.Context, int) ([]*domain.User, error)
+		Purge(context.Context, int64) error
 	}
 
 	Foods interface {
-		Search(context.Context, domain.FoodFilter) ([]*domain.Food, error)
+		Search(context.Context, domain.FoodFilter) (*domain.Page[*domain.Food], error)
+		ListNutrients(context.Context) ([]*domain.Nutrient, error)
 		GetPaginated(context.Context, int, int) ([]*domain.Food, error)
 		GetByID(context.Context, int64) (*domain.Food, error)
+		GetByBarcode(context.Context, string) (*domain.Food, error)
+		ListByOwner(context.Context, int64) ([]*domain.Food, error)
 		Create(context.Context, *domain.Food) error
 		Update(context.Context, *domain.Food) error
 		Delete(context.Context, int64) error
+		SetVisibility(context.Context, int64, string, string) error
+		CreatePortion(context.Context, int64, *domain.FoodPortion) error
+		GetPortion(context.Context, int64, int64) (*domain.FoodPortion, error)
+		DeletePortion(context.Context, int64, int64) error
+		ListRevisions(context.Context, int64) ([]*domain.FoodRevision, error)
+		GetRevision(context.Context, int64, int) (*domain.FoodRevision, error)
+		FindDuplicates(context.Context, float64, int) ([]*domain.FoodDuplicate, error)
+		Merge(context.Context, int64, []int64) (*domain.FoodMergeResult, error)
+		FindForImport(context.Context, []string, []string) (map[string]*domain.Food, map[string][]*domain.Food, error)
+	}
+
+	FoodImports interface {
+		Create(context.Context, *domain.FoodImport) error
+		GetByID(context.Context, int64) (*domain.FoodImport, error)
+		Claim(context.Context, int, time.Duration) ([]*domain.FoodImport, error)
+		SetTotalRows(context.Context, int64, int) error
+		SaveBatch(context.Context, *domain.FoodImport, []*domain.FoodImportRow, time.Duration) error
+		MarkCompleted(context.Context, int64) error
+		MarkFailed(context.Context, int64, error, bool) error
+		ListErrors(context.Context, domain.FoodImportErrorFilter) ([]*domain.FoodImportError, error)
+	}
+
+	Recipes interface {
+		Create(context.Context, *domain.Recipe) error
+		Update(context.Context, *domain.Recipe) error
+		GetByFoodID(context.Context, int64) (*domain.Recipe, error)
+		ListIDsByIngredient(context.Context, []int64) ([]int64, error)
 	}
 
 	Diary interface {
 		GetSummary(context.Context, int64, time.Time) (*domain.DailySummary, error)
 		GetEntries(context.Context, int64, time.Time) ([]*domain.FoodDiary, error)
+		GetAllByUser(context.Context, int64) ([]*domain.FoodDiary, error)
+		List(context.Context, domain.DiaryFilter) (*domain.Page[*domain.FoodDiary], error)
 		GetUserEntry(context.Context, int64, int64) (*domain.FoodDiary, error)
 		GetEntry(context.Context, int64) (*domain.FoodDiary, error)
 		Create(context.Context, *domain.FoodDiary) error
 		Update(context.Context, *domain.FoodDiary) error
 		Delete(context.Context, int64) error
 	}
+
+	Sessions interface {
+		Create(context.Context, *domain.Session) error
+		GetByID(context.Context, int64) (*domain.Session, error)
+		ListActiveByUser(context.Context, int64) ([]*domain.Session, error)
+		Touch(context.Context, int64) error
+		Revoke(context.Context, int64) error
+		RevokeForUser(context.Context, int64, int64) error
+		RevokeAllForUser(context.Context, int64) error
+		CreateRefreshToken(context.Context, *domain.RefreshToken) error
+		ConsumeRefreshToken(context.Context, []byte) (*domain.RefreshToken, error)
+		RevokeToken(context.Context, string, time.Time) error
+		ConsumeToken(context.Context, string, time.Time) (bool, error)
+		IsRevoked(context.Context, int64, string) (bool, error)
+	}
+
+	TwoFactor interface {
+		GetTOTP(context.Context, int64) (*domain.TOTPSecret, error)
+		SetPendingTOTP(context.Context, int64, []byte) error
+		EnableTOTP(context.Context, int64) error
+		DisableTOTP(context.Context, int64) error
+		UseTOTPStep(context.Context, int64, int64) (bool, error)
+		ReplaceRecoveryCodes(context.Context, int64, [][]byte) error
+		ConsumeRecoveryCode(context.Context, int64, []byte) error
+	}
+
+	APIKeys interface {
+		Create(context.Context, *domain.APIKey) error
+		ListByUser(context.Context, int64) ([]*domain.APIKey, error)
+		GetActiveByHash(context.Context, []byte) (*domain.APIKey, domain.Role, error)
+		TouchLastUsed(context.Context, int64) error
+		Revoke(context.Context, int64, int64) error
+	}
+
+	SigningKeys interface {
+		ListValid(context.Context) ([]*domain.SigningKey, error)
+		CreateIfUncovered(context.Context, *domain.SigningKey, time.Time) (bool, error)
+		DeleteExpired(context.Context) error
+	}
+
+	Identities interface {
+		GetByProviderSubject(context.Context, string, string) (*domain.UserIdentity, error)
+		ListByUser(context.Context, int64) ([]*domain.UserIdentity, error)
+		Create(context.Context, *domain.UserIdentity) error
+		CreateWithUser(context.Context, *domain.User, *domain.UserIdentity) error
+		TouchLogin(context.Context, int64) error
+		CreateAuthRequest(context.Context, *domain.OIDCAuthRequest) error
+		ConsumeAuthRequest(context.Context, []byte) (*domain.OIDCAuthRequest, error)
+	}
+
+	Audit interface {
+		Create(context.Context, *domain.AuditEvent) error
+		List(context.Context, domain.AuditFilter) (*domain.Page[*domain.AuditEvent], error)
+	}
+
+	DataExports interface {
+		Create(context.Context, *domain.DataExport) error
+		GetForUser(context.Context, int64, int64) (*domain.DataExport, error)
+		Claim(context.Context, int, time.Duration) ([]*domain.DataExport, error)
+		MarkCompleted(context.Context, int64, string, time.Time) error
+		MarkFailed(context.Context, int64, error, bool) error
+		ListExpired(context.Context, int) ([]*domain.DataExport, error)
+		MarkExpired(context.Context, int64) error
+	}
+
+	Tokens interface {
+		Create(context.Context, *domain.UserToken) error
+		Consume(context.Context, string, []byte) (*domain.UserToken, error)
+		Invalidate(context.Context, int64, string) error
+	}
+
+	LoginAttempts interface {
+		LockedUntil(context.Context, string) (*time.Time, error)
+		RecordFailure(context.Context, string, time.Duration) (int, error)
+		Lock(context.Context, string, time.Time) error
+		Reset(context.Context, string) error
+	}
+
+	Outbox interface {
+		Enqueue(context.Context, domain.Email) error
+		Claim(context.Context, int, time.Duration) ([]*domain.OutboxEmail, error)
+		MarkSent(context.Context, int64) error
+		MarkFailed(context.Context, int64, error, time.Time) error
+		MarkDead(context.Context, int64, error) error
+	}
 }
 
 func NewStorage(db *sql.DB) Storage {
 	return Storage{
-		Users: &UserStore{db},
-		Foods: &FoodStore{db},
-		Diary: &DiaryStore{db},
+		Users:         &UserStore{db},
+		Foods:         &FoodStore{db},
+		FoodImports:   &FoodImportStore{db},
+		Recipes:       &RecipeStore{db},
+		Diary:         &DiaryStore{db},
+		Sessions:      &SessionStore{db},
+		TwoFactor:     &TwoFactorStore{db},
+		APIKeys:       &APIKeyStore{db},
+		SigningKeys:   &SigningKeyStore{db},
+		Identities:    &IdentityStore{db},
+		Audit:         &AuditStore{db},
+		DataExports:   &DataExportStore{db},
+		Tokens:        &UserTokenStore{db},
+		Outbox:        &OutboxStore{db},
+		LoginAttempts: &LoginAttemptStore{db},
 	}
 }
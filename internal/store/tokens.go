@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+type UserTokenStore struct {
+	db *sql.DB
+}
+
+func (s *UserTokenStore) Create(ctx context.Context, token *domain.UserToken) error {
+	query := `
+	INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, created_at
+	`
+
+	return s.db.QueryRowContext(ctx, query,
+		token.UserID,
+		token.Purpose,
+		token.TokenHash,
+		token.ExpiresAt,
+	).Scan(
+		&token.ID,
+		&token.CreatedAt,
+	)
+}
+
+// Consume menandai token terpakai secara atomik, token yang sudah dipakai
+// atau kadaluarsa dianggap tidak ada.
+func (s *UserTokenStore) Consume(ctx context.Context, purpose string, tokenHash []byte) (*domain.UserToken, error) {
+	query := `
+	UPDATE user_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1
+			AND purpose = $2
+			AND used_at IS NULL
+			AND expires_at > NOW()
+		RETURNING id, user_id, purpose, expires_at, used_at, created_at
+	`
+
+	token := &domain.UserToken{TokenHash: tokenHash}
+	err := s.db.QueryRowContext(ctx, query, tokenHash, purpose).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Purpose,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// Invalidate menghanguskan semua token user yang belum terpakai untuk purpose tertentu
+func (s *UserTokenStore) Invalidate(ctx context.Context, userID int64, purpose string) error {
+	query := `
+	UPDATE user_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+	`
+
+	_, err := s.db.ExecContext(ctx, query, userID, purpose)
+	return err
+}
@@ -0,0 +1,158 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MyFirstGo/internal/domain"
+)
+
+type TwoFactorStore struct {
+	db *sql.DB
+}
+
+func (s *TwoFactorStore) GetTOTP(ctx context.Context, userID int64) (*domain.TOTPSecret, error) {
+	query := `
+	SELECT totp_secret, totp_enabled_at, totp_last_step
+	FROM users
+	WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	secret := &domain.TOTPSecret{}
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(
+		&secret.Secret,
+		&secret.EnabledAt,
+		&secret.LastStep,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// SetPendingTOTP menyimpan secret baru yang belum aktif sampai dikonfirmasi
+func (s *TwoFactorStore) SetPendingTOTP(ctx context.Context, userID int64, secret []byte) error {
+	query := `
+	UPDATE users
+		SET totp_secret = $2, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	return s.execOne(ctx, query, userID, secret)
+}
+
+func (s *TwoFactorStore) EnableTOTP(ctx context.Context, userID int64) error {
+	query := `
+	UPDATE users
+		SET totp_enabled_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND totp_secret IS NOT NULL
+	`
+
+	return s.execOne(ctx, query, userID)
+}
+
+func (s *TwoFactorStore) DisableTOTP(ctx context.Context, userID int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+	UPDATE users
+		SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep mencatat time step yang sudah dipakai. Mengembalikan false jika
+// step tersebut (atau yang lebih baru) sudah pernah dipakai, artinya kode di-replay.
+func (s *TwoFactorStore) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	query := `
+	UPDATE users
+		SET totp_last_step = $2
+		WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)
+	`
+
+	res, err := s.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ReplaceRecoveryCodes menghapus kode lama dan menyimpan hash kode baru
+func (s *TwoFactorStore) ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes [][]byte) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, hash := range hashes {
+		if _, err := stmt.ExecContext(ctx, userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *TwoFactorStore) ConsumeRecoveryCode(ctx context.Context, userID int64, hash []byte) error {
+	query := `
+	UPDATE recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	return s.execOne(ctx, query, userID, hash)
+}
+
+func (s *TwoFactorStore) execOne(ctx context.Context, query string, args ...any) error {
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
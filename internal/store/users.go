@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/MyFirstGo/internal/domain"
 )
@@ -12,8 +14,29 @@ type UserStore struct {
 	db *sql.DB
 }
 
-func (s *UserStore) GetPaginated(ctx context.Context, limit, offset int) ([]*domain.User, error) {
-	queryFoods := `
+func (s *UserStore) GetPaginated(ctx context.Context, p domain.PageRequest) (*domain.Page[*domain.User], error) {
+	ks, err := newKeyset(p.Cursor, sortKey{"id", false})
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if p.WithTotal {
+		var count int64
+		queryCount := `SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`
+		if err := s.db.QueryRowContext(ctx, queryCount).Scan(&count); err != nil {
+			return nil, err
+		}
+		total = &count
+	}
+
+	where := "WHERE deleted_at IS NULL"
+	cond, args := ks.where(1)
+	if cond != "" {
+		where += " AND " + cond
+	}
+
+	queryUsers := fmt.Sprintf(`
 	SELECT
 			id,
 			username,
@@ -23,23 +46,32 @@ func (s *UserStore) GetPaginated(ctx context.Context, limit, offset int) ([]*dom
 			date_of_birth,
 			activity_level,
 			gender,
+			role,
+			email_verified_at,
+			totp_enabled_at,
+			deletion_scheduled_at,
 			created_at,
-			updated_at
+			updated_at,
+			%s
 	FROM users
-	WHERE deleted_at IS NULL
-	LIMIT $1 OFFSET $2
-	`
+	%s
+	ORDER BY %s
+	LIMIT $%d
+	`, ks.columns(), where, ks.orderBy(), len(args)+1)
+	args = append(args, p.Limit+1)
 
-	rows, err := s.db.QueryContext(ctx, queryFoods, limit, offset)
+	rows, err := s.db.QueryContext(ctx, queryUsers, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var users []*domain.User
+	var keys [][]string
 
 	for rows.Next() {
 		u := &domain.User{}
+		key := make([]string, 1)
 		if err := rows.Scan(
 			&u.ID,
 			&u.Username,
@@ -49,15 +81,32 @@ func (s *UserStore) GetPaginated(ctx context.Context, limit, offset int) ([]*dom
 			&u.DateOfBirth,
 			&u.ActivityLevel,
 			&u.Gender,
+			&u.Role,
+			&u.EmailVerifiedAt,
+			&u.TOTPEnabledAt,
+			&u.DeletionScheduledAt,
 			&u.CreatedAt,
-			&u.UpdatedAt); err != nil {
+			&u.UpdatedAt,
+			&key[0]); err != nil {
 			return nil, err
 		}
 
 		users = append(users, u)
+		keys = append(keys, key)
 	}
 
-	return users, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if users == nil {
+		users = []*domain.User{}
+	}
+
+	page := keysetPage(ks, users, keys, p.Limit)
+	page.Total = total
+
+	return page, nil
 }
 
 func (s *UserStore) GetAll(ctx context.Context) ([]domain.User, error) {
@@ -71,6 +120,10 @@ func (s *UserStore) GetAll(ctx context.Context) ([]domain.User, error) {
 			date_of_birth,
 			activity_level,
 			gender,
+			role,
+			email_verified_at,
+			totp_enabled_at,
+			deletion_scheduled_at,
 			created_at,
 			updated_at
 		FROM users
@@ -97,6 +150,10 @@ func (s *UserStore) GetAll(ctx context.Context) ([]domain.User, error) {
 			&user.DateOfBirth,
 			&user.ActivityLevel,
 			&user.Gender,
+			&user.Role,
+			&user.EmailVerifiedAt,
+			&user.TOTPEnabledAt,
+			&user.DeletionScheduledAt,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -125,10 +182,15 @@ func (s *UserStore) GetByID(ctx context.Context, userID int64) (*domain.User, er
 			date_of_birth,
 			activity_level,
 			gender,
+			role,
+			email_verified_at,
+			totp_enabled_at,
+			deletion_scheduled_at,
 			created_at,
 			updated_at
 		FROM users
 		WHERE id = $1
+			AND deleted_at IS NULL
 	`
 
 	user := &domain.User{}
@@ -141,6 +203,10 @@ func (s *UserStore) GetByID(ctx context.Context, userID int64) (*domain.User, er
 		&user.DateOfBirth,
 		&user.ActivityLevel,
 		&user.Gender,
+		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.TOTPEnabledAt,
+		&user.DeletionScheduledAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -167,6 +233,10 @@ func (s *UserStore) GetByEmail(ctx context.Context, email string) (*domain.User,
 			date_of_birth,
 			activity_level,
 			gender,
+			role,
+			email_verified_at,
+			totp_enabled_at,
+			deletion_scheduled_at,
 			created_at,
 			updated_at
 		FROM users
@@ -185,6 +255,10 @@ func (s *UserStore) GetByEmail(ctx context.Context, email string) (*domain.User,
 		&user.DateOfBirth,
 		&user.ActivityLevel,
 		&user.Gender,
+		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.TOTPEnabledAt,
+		&user.DeletionScheduledAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -199,6 +273,21 @@ func (s *UserStore) GetByEmail(ctx context.Context, email string) (*domain.User,
 	return user, nil
 }
 
+func (s *UserStore) GetPasswordHash(ctx context.Context, userID int64) (string, error) {
+	query := `SELECT password FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+	var password string
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(&password)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	return password, nil
+}
+
 func (s *UserStore) Create(ctx context.Context, user *domain.User) error {
 	query := `
 	INSERT INTO users (
@@ -211,7 +300,7 @@ func (s *UserStore) Create(ctx context.Context, user *domain.User) error {
 		activity_level,
 		gender)
 	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-	RETURNING id, created_at, updated_at
+	RETURNING id, role, email_verified_at, totp_enabled_at, created_at, updated_at
 	`
 
 	err := s.db.QueryRowContext(ctx,
@@ -226,6 +315,9 @@ func (s *UserStore) Create(ctx context.Context, user *domain.User) error {
 		user.Gender,
 	).Scan(
 		&user.ID,
+		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.TOTPEnabledAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -248,6 +340,8 @@ func (s *UserStore) Update(ctx context.Context, user *domain.User) error {
 					date_of_birth = $6,
 					activity_level = $7,
 					gender = $8,
+					-- Ganti email berarti harus verifikasi ulang
+					email_verified_at = CASE WHEN email = $3 THEN email_verified_at END,
 					updated_at = NOW()
         WHERE id = $1
     `
@@ -306,11 +400,100 @@ func (s *UserStore) UpdateAvatar(ctx context.Context, userID int64, objectName s
 	return nil
 }
 
+func (s *UserStore) UpdatePassword(ctx context.Context, userID int64, password string) error {
+	query := `
+        UPDATE users
+        SET
+					password = $2,
+					updated_at = NOW()
+        WHERE id = $1 AND deleted_at IS NULL
+    `
+	res, err := s.db.ExecContext(ctx, query, userID, password)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *UserStore) GetAvatar(ctx context.Context, userID int64) (*string, error) {
+	query := `SELECT avatar FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+	var avatar *string
+	if err := s.db.QueryRowContext(ctx, query, userID).Scan(&avatar); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return avatar, nil
+}
+
+func (s *UserStore) MarkEmailVerified(ctx context.Context, userID int64) error {
+	query := `
+        UPDATE users
+        SET
+					email_verified_at = COALESCE(email_verified_at, NOW()),
+					updated_at = NOW()
+        WHERE id = $1 AND deleted_at IS NULL
+    `
+	res, err := s.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *UserStore) UpdateRole(ctx context.Context, userID int64, role domain.Role) error {
+	query := `
+        UPDATE users
+        SET
+					role = $2,
+					updated_at = NOW()
+        WHERE id = $1 AND deleted_at IS NULL
+    `
+	res, err := s.db.ExecContext(ctx, query, userID, role)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
 func (s *UserStore) Delete(ctx context.Context, userID int64) error {
 	query := `
 				UPDATE users
-				SET deleted_at = NOW()
-				WHERE id = $1
+				SET deleted_at = NOW(), deletion_scheduled_at = NOW()
+				WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	res, err := s.db.ExecContext(ctx, query, userID)
@@ -329,3 +512,131 @@ func (s *UserStore) Delete(ctx context.Context, userID int64) error {
 
 	return nil
 }
+
+func (s *UserStore) ScheduleDeletion(ctx context.Context, userID int64, at time.Time) error {
+	query := `
+	UPDATE users
+		SET deletion_scheduled_at = $2, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	res, err := s.db.ExecContext(ctx, query, userID, at)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CancelDeletion membatalkan jadwal penghapusan akun yang belum dihapus admin,
+// mengembalikan false jika memang tidak ada jadwal yang dibatalkan
+func (s *UserStore) CancelDeletion(ctx context.Context, userID int64) (bool, error) {
+	query := `
+	UPDATE users
+		SET deletion_scheduled_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND deletion_scheduled_at IS NOT NULL
+	`
+
+	res, err := s.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ListDueForPurge mengambil user yang masa tenggang penghapusannya sudah lewat
+func (s *UserStore) ListDueForPurge(ctx context.Context, limit int) ([]*domain.User, error) {
+	query := `
+	SELECT id, username, email, deletion_scheduled_at
+	FROM users
+	WHERE deletion_scheduled_at <= NOW()
+	ORDER BY deletion_scheduled_at
+	LIMIT $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*domain.User{}
+	for rows.Next() {
+		user := &domain.User{}
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.DeletionScheduledAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// Purge menghapus user secara permanen. Data turunan (diary, session, API key, dst.)
+// ikut terhapus lewat ON DELETE CASCADE, sedangkan audit log yang menyangkut user
+// dianonimkan dalam transaksi yang sama karena tidak boleh dihapus.
+func (s *UserStore) Purge(ctx context.Context, userID int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Kunci baris user dan cek ulang jadwal supaya user yang baru saja membatalkan
+	// penghapusan tidak ikut terhapus, CancelDeletion menunggu sampai tx ini selesai
+	queryLock := `SELECT id FROM users WHERE id = $1 AND deletion_scheduled_at <= NOW() FOR UPDATE`
+	var id int64
+	if err := tx.QueryRowContext(ctx, queryLock, userID).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	queryActor := `
+	UPDATE audit_events
+		SET actor_id = NULL, actor_api_key_id = NULL, ip_address = NULL, user_agent = NULL, changes = NULL
+		WHERE actor_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, queryActor, userID); err != nil {
+		return err
+	}
+
+	queryTarget := `
+	UPDATE audit_events
+		SET changes = NULL
+		WHERE target_type = $2 AND target_id = $1 AND changes IS NOT NULL
+	`
+	if _, err := tx.ExecContext(ctx, queryTarget, userID, domain.AuditTargetUser); err != nil {
+		return err
+	}
+
+	// Food private dan yang masih diajukan ikut dihapus, food yang sudah publik tetap di katalog
+	// dengan owner_id NULL (ON DELETE SET NULL)
+	queryFoods := `DELETE FROM foods WHERE owner_id = $1 AND visibility <> $2`
+	if _, err := tx.ExecContext(ctx, queryFoods, userID, domain.FoodVisibilityPublic); err != nil {
+		return err
+	}
+
+	queryDelete := `DELETE FROM users WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, queryDelete, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type accountPurger interface {
+	PurgeDeleted(context.Context) error
+}
+
+// AccountPurger menghapus permanen akun yang masa tenggang penghapusannya sudah lewat
+type AccountPurger struct {
+	users    accountPurger
+	interval time.Duration
+}
+
+func NewAccountPurger(users accountPurger, interval time.Duration) *AccountPurger {
+	return &AccountPurger{
+		users:    users,
+		interval: interval,
+	}
+}
+
+func (p *AccountPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.users.PurgeDeleted(ctx); err != nil {
+			slog.Error("failed to purge deleted accounts", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type exportProcessor interface {
+	ProcessPending(context.Context) error
+	PurgeExpired(context.Context) error
+}
+
+// DataExporter membuat file export data user di background dan menghapus file yang kedaluwarsa
+type DataExporter struct {
+	exports  exportProcessor
+	interval time.Duration
+}
+
+func NewDataExporter(exports exportProcessor, interval time.Duration) *DataExporter {
+	return &DataExporter{
+		exports:  exports,
+		interval: interval,
+	}
+}
+
+func (e *DataExporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.exports.ProcessPending(ctx); err != nil {
+			slog.Error("failed to process data exports", "error", err)
+		}
+
+		if err := e.exports.PurgeExpired(ctx); err != nil {
+			slog.Error("failed to purge expired data exports", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type importProcessor interface {
+	ProcessPending(context.Context) error
+}
+
+// FoodImporter memproses file import food katalog yang diunggah admin di background
+type FoodImporter struct {
+	imports  importProcessor
+	interval time.Duration
+}
+
+func NewFoodImporter(imports importProcessor, interval time.Duration) *FoodImporter {
+	return &FoodImporter{
+		imports:  imports,
+		interval: interval,
+	}
+}
+
+func (i *FoodImporter) Run(ctx context.Context) {
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := i.imports.ProcessPending(ctx); err != nil {
+			slog.Error("failed to process food imports", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
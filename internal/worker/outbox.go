@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/MyFirstGo/internal/domain"
+	"github.com/MyFirstGo/internal/store"
+)
+
+const (
+	outboxBatchSize   = 20
+	outboxLease       = 5 * time.Minute
+	outboxMaxAttempts = 15
+	// Jeda back-off berhenti bertambah setelah 2^10 menit (sekitar 17 jam)
+	outboxMaxBackoffShift = 10
+)
+
+// EmailDispatcher mengirim email dari tabel email_outbox lewat Mailer
+type EmailDispatcher struct {
+	store    store.Storage
+	mailer   domain.Mailer
+	interval time.Duration
+}
+
+func NewEmailDispatcher(store store.Storage, mailer domain.Mailer, interval time.Duration) *EmailDispatcher {
+	return &EmailDispatcher{
+		store:    store,
+		mailer:   mailer,
+		interval: interval,
+	}
+}
+
+func (d *EmailDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *EmailDispatcher) dispatch(ctx context.Context) {
+	emails, err := d.store.Outbox.Claim(ctx, outboxBatchSize, outboxLease)
+	if err != nil {
+		slog.Error("failed to claim outbox emails", "error", err)
+		return
+	}
+
+	for _, e := range emails {
+		if err := d.mailer.Send(ctx, e.Email); err != nil {
+			slog.Error("failed to send email", "id", e.ID, "attempts", e.Attempts+1, "error", err)
+
+			if e.Attempts+1 >= outboxMaxAttempts {
+				if err := d.store.Outbox.MarkDead(ctx, e.ID, err); err != nil {
+					slog.Error("failed to mark outbox email as dead", "id", e.ID, "error", err)
+				}
+				continue
+			}
+
+			// Exponential back-off, jeda maksimal dibatasi sekitar 17 jam
+			retryAt := time.Now().Add(time.Minute << min(e.Attempts, outboxMaxBackoffShift))
+			if err := d.store.Outbox.MarkFailed(ctx, e.ID, err, retryAt); err != nil {
+				slog.Error("failed to mark outbox email as failed", "id", e.ID, "error", err)
+			}
+			continue
+		}
+
+		if err := d.store.Outbox.MarkSent(ctx, e.ID); err != nil {
+			slog.Error("failed to mark outbox email as sent", "id", e.ID, "error", err)
+		}
+	}
+}
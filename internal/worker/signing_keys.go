@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type keyRotator interface {
+	Rotate(context.Context) error
+}
+
+// SigningKeyRotator merotasi key JWT secara berkala dan memuat ulang key
+// yang dibuat oleh instance lain
+type SigningKeyRotator struct {
+	keys     keyRotator
+	interval time.Duration
+}
+
+func NewSigningKeyRotator(keys keyRotator, interval time.Duration) *SigningKeyRotator {
+	return &SigningKeyRotator{
+		keys:     keys,
+		interval: interval,
+	}
+}
+
+func (r *SigningKeyRotator) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.keys.Rotate(ctx); err != nil {
+				slog.Error("failed to rotate signing keys", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent text,
+    ip_address varchar(45),
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    last_seen_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    expires_at timestamp(0) with time zone NOT NULL,
+    revoked_at timestamp(0) with time zone
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+
+-- Refresh token dirotasi setiap dipakai, yang disimpan hanya hash SHA-256 nya
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id bigserial PRIMARY KEY,
+    session_id bigint NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    token_hash bytea NOT NULL UNIQUE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    used_at timestamp(0) with time zone
+);
+
+-- Access token (jti) yang dicabut sebelum masa berlakunya habis
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti uuid PRIMARY KEY,
+    expires_at timestamp(0) with time zone NOT NULL
+);
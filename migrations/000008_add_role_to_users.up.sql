@@ -0,0 +1,4 @@
+-- Admin pertama dipromosikan manual: UPDATE users SET role = 'admin' WHERE email = '...';
+ALTER TABLE users
+ADD COLUMN role varchar(20) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'nutritionist', 'admin'));
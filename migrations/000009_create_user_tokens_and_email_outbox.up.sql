@@ -0,0 +1,27 @@
+-- Token sekali pakai (reset password, dll). Yang disimpan hanya hash SHA-256 nya
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose varchar(30) NOT NULL,
+    token_hash bytea NOT NULL UNIQUE,
+    expires_at timestamp(0) with time zone NOT NULL,
+    used_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_tokens_user_purpose ON user_tokens (user_id, purpose);
+
+-- Email tidak dikirim langsung dari request, tapi ditulis ke outbox lalu dikirim worker
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id bigserial PRIMARY KEY,
+    recipient varchar(255) NOT NULL,
+    subject varchar(255) NOT NULL,
+    body text NOT NULL,
+    attempts int NOT NULL DEFAULT 0,
+    last_error text,
+    send_after timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    sent_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_email_outbox_pending ON email_outbox (send_after) WHERE sent_at IS NULL;
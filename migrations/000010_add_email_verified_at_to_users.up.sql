@@ -0,0 +1,5 @@
+ALTER TABLE users
+ADD COLUMN email_verified_at timestamp(0) with time zone;
+
+-- User lama dianggap sudah terverifikasi supaya tidak terkunci setelah migrasi
+UPDATE users SET email_verified_at = created_at;
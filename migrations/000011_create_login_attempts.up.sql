@@ -0,0 +1,8 @@
+-- Counter gagal login dibagi per akun ('account:<email>') dan per IP ('ip:<addr>')
+-- disimpan di Postgres supaya semua instance API memakai counter yang sama
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key varchar(320) PRIMARY KEY,
+    failures int NOT NULL DEFAULT 0,
+    last_failed_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    locked_until timestamp(0) with time zone
+);
@@ -0,0 +1,15 @@
+-- totp_secret terenkripsi (AES-GCM), totp_enabled_at NULL berarti enrollment belum dikonfirmasi
+ALTER TABLE users
+ADD COLUMN totp_secret bytea,
+ADD COLUMN totp_enabled_at timestamp(0) with time zone,
+ADD COLUMN totp_last_step bigint;
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash bytea NOT NULL,
+    used_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes (user_id);
@@ -0,0 +1,15 @@
+-- Key untuk menandatangani JWT. private_key terenkripsi (AES-GCM), public_key dalam format PKIX DER.
+-- Key dipublikasikan di JWKS sejak dibuat, dipakai menandatangani antara activates_at dan retires_at,
+-- dan tetap dipakai untuk verifikasi sampai expires_at.
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id varchar(64) PRIMARY KEY,
+    algorithm varchar(16) NOT NULL,
+    private_key bytea NOT NULL,
+    public_key bytea NOT NULL,
+    activates_at timestamp(0) with time zone NOT NULL,
+    retires_at timestamp(0) with time zone NOT NULL,
+    expires_at timestamp(0) with time zone NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_signing_keys_expires_at ON signing_keys (expires_at);
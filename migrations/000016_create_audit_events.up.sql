@@ -0,0 +1,29 @@
+-- actor_id sengaja tanpa foreign key supaya event tetap ada setelah user dihapus
+CREATE TABLE IF NOT EXISTS audit_events (
+    id bigserial PRIMARY KEY,
+    actor_id bigint,
+    actor_api_key_id bigint,
+    action varchar(100) NOT NULL,
+    target_type varchar(50) NOT NULL,
+    target_id bigint,
+    changes jsonb,
+    ip_address varchar(64),
+    user_agent text,
+    request_id varchar(100),
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_events_actor_id ON audit_events (actor_id, created_at DESC);
+CREATE INDEX idx_audit_events_target ON audit_events (target_type, target_id, created_at DESC);
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at DESC);
+
+-- Audit log hanya boleh ditambah, tidak boleh diubah atau dihapus
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS data_exports (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status varchar(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'expired')),
+    object_key varchar(255),
+    last_error text,
+    attempts int NOT NULL DEFAULT 0,
+    locked_until timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    completed_at timestamp(0) with time zone,
+    expires_at timestamp(0) with time zone
+);
+
+-- Satu user hanya boleh punya satu export yang sedang berjalan
+CREATE UNIQUE INDEX idx_data_exports_user_in_progress
+ON data_exports (user_id)
+WHERE status IN ('pending', 'processing');
+
+CREATE INDEX idx_data_exports_status ON data_exports (status, id);
@@ -0,0 +1,28 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at timestamp(0) with time zone;
+
+CREATE INDEX idx_users_deletion_scheduled_at ON users (deletion_scheduled_at)
+WHERE deletion_scheduled_at IS NOT NULL;
+
+-- Audit log tetap append-only, kecuali anonimisasi data pribadi saat akun dihapus permanen:
+-- kolom actor, ip, user agent dan changes hanya boleh diubah menjadi NULL
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.id = OLD.id
+        AND NEW.action = OLD.action
+        AND NEW.target_type = OLD.target_type
+        AND NEW.target_id IS NOT DISTINCT FROM OLD.target_id
+        AND NEW.request_id IS NOT DISTINCT FROM OLD.request_id
+        AND NEW.created_at = OLD.created_at
+        AND (NEW.actor_id IS NULL OR NEW.actor_id = OLD.actor_id)
+        AND (NEW.actor_api_key_id IS NULL OR NEW.actor_api_key_id = OLD.actor_api_key_id)
+        AND (NEW.ip_address IS NULL OR NEW.ip_address = OLD.ip_address)
+        AND (NEW.user_agent IS NULL OR NEW.user_agent = OLD.user_agent)
+        AND (NEW.changes IS NULL OR NEW.changes = OLD.changes)
+    THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_foods_name_trgm;
+DROP INDEX IF EXISTS idx_foods_search_vector;
+ALTER TABLE foods DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,13 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Nama diberi bobot lebih tinggi dari deskripsi saat ranking
+ALTER TABLE foods ADD COLUMN IF NOT EXISTS search_vector tsvector
+GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+) STORED;
+
+CREATE INDEX idx_foods_search_vector ON foods USING GIN (search_vector);
+
+-- Untuk pencarian yang toleran typo, misal "chiken breast"
+CREATE INDEX idx_foods_name_trgm ON foods USING GIN (name gin_trgm_ops);
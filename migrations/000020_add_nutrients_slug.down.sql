@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_nutrients_slug;
+ALTER TABLE nutrients DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,10 @@
+-- Slug dipakai sebagai nama parameter filter & sort di API, misal protein_min=20&sort=-protein
+ALTER TABLE nutrients ADD COLUMN IF NOT EXISTS slug varchar(50)
+GENERATED ALWAYS AS (
+    CASE
+        WHEN name = 'Caloric Value' THEN 'calories'
+        ELSE trim(BOTH '_' FROM lower(regexp_replace(name, '[^a-zA-Z0-9]+', '_', 'g')))
+    END
+) STORED;
+
+CREATE UNIQUE INDEX idx_nutrients_slug ON nutrients (slug);
@@ -0,0 +1,8 @@
+-- Barcode disimpan sebagai GTIN-14 (dengan nol di depan) supaya UPC-A dan EAN-13 yang sama tidak dobel
+CREATE TABLE IF NOT EXISTS food_barcodes (
+    gtin char(14) PRIMARY KEY CHECK (gtin ~ '^[0-9]{14}$'),
+    food_id bigint NOT NULL REFERENCES foods(id) ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_food_barcodes_food_id ON food_barcodes (food_id);
@@ -0,0 +1,17 @@
+-- Nama food per bahasa dan alias bebas (misal "tempe", "nasi goreng"), foods.name tetap nama bahasa Inggris
+CREATE TABLE IF NOT EXISTS food_names (
+    id bigserial PRIMARY KEY,
+    food_id bigint NOT NULL REFERENCES foods(id) ON DELETE CASCADE,
+    locale varchar(10) NOT NULL,
+    name varchar(255) NOT NULL,
+    is_alias boolean NOT NULL DEFAULT false,
+    search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', name)) STORED,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+-- Satu nama tampilan per bahasa, alias boleh banyak
+CREATE UNIQUE INDEX idx_food_names_display ON food_names (food_id, locale) WHERE NOT is_alias;
+CREATE UNIQUE INDEX idx_food_names_unique ON food_names (food_id, locale, lower(name));
+
+CREATE INDEX idx_food_names_search_vector ON food_names USING GIN (search_vector);
+CREATE INDEX idx_food_names_name_trgm ON food_names USING GIN (name gin_trgm_ops);
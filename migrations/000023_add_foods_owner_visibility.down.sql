@@ -0,0 +1,5 @@
+DELETE FROM foods WHERE visibility <> 'public';
+
+ALTER TABLE foods
+    DROP COLUMN IF EXISTS visibility,
+    DROP COLUMN IF EXISTS owner_id;
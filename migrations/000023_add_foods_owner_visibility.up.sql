@@ -0,0 +1,9 @@
+-- Food buatan user bersifat private sampai diajukan (submitted) dan disetujui editor (public).
+-- owner_id NULL untuk katalog bawaan, di-set NULL juga saat pemiliknya dihapus permanen.
+ALTER TABLE foods
+    ADD COLUMN owner_id bigint REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN visibility varchar(20) NOT NULL DEFAULT 'public'
+        CHECK (visibility IN ('private', 'submitted', 'public'));
+
+CREATE INDEX idx_foods_owner_id ON foods (owner_id) WHERE owner_id IS NOT NULL;
+CREATE INDEX idx_foods_submitted ON foods (updated_at) WHERE visibility = 'submitted';
@@ -0,0 +1,19 @@
+-- Resep disimpan sebagai food (supaya bisa dicari dan dicatat di diary seperti food biasa),
+-- tabel ini menyimpan yield dan bahan untuk menghitung ulang nutrient-nya
+CREATE TABLE IF NOT EXISTS recipes (
+    food_id bigint PRIMARY KEY REFERENCES foods(id) ON DELETE CASCADE,
+    yield_servings numeric(10,2) CHECK (yield_servings > 0),
+    yield_weight numeric(10,2) CHECK (yield_weight > 0),
+    CHECK ((yield_servings IS NULL) <> (yield_weight IS NULL))
+);
+
+CREATE TABLE IF NOT EXISTS recipe_ingredients (
+    id bigserial PRIMARY KEY,
+    recipe_id bigint NOT NULL REFERENCES recipes(food_id) ON DELETE CASCADE,
+    food_id bigint NOT NULL REFERENCES foods(id) ON DELETE CASCADE,
+    quantity numeric(10,2) NOT NULL CHECK (quantity > 0),
+    position int NOT NULL
+);
+
+CREATE INDEX idx_recipe_ingredients_recipe_id ON recipe_ingredients (recipe_id, position);
+CREATE INDEX idx_recipe_ingredients_food_id ON recipe_ingredients (food_id);
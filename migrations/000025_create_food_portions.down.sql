@@ -0,0 +1,5 @@
+ALTER TABLE food_diaries
+    DROP COLUMN IF EXISTS quantity,
+    DROP COLUMN IF EXISTS portion_id;
+
+DROP TABLE IF EXISTS food_portions;
@@ -0,0 +1,16 @@
+-- Takaran rumah tangga per food, misal "slice" = 28 g atau "cup" = 240 g
+CREATE TABLE IF NOT EXISTS food_portions (
+    id bigserial PRIMARY KEY,
+    food_id bigint NOT NULL REFERENCES foods(id) ON DELETE CASCADE,
+    name varchar(50) NOT NULL,
+    grams numeric(10,2) NOT NULL CHECK (grams > 0),
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_food_portions_name ON food_portions (food_id, lower(name));
+
+-- Diary bisa dicatat sebagai quantity x portion. amount_consumed tetap diisi (dalam serving_unit
+-- food) sebagai fallback jika portion dihapus.
+ALTER TABLE food_diaries
+    ADD COLUMN portion_id bigint REFERENCES food_portions(id) ON DELETE SET NULL,
+    ADD COLUMN quantity numeric(10,2) CHECK (quantity > 0);
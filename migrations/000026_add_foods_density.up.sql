@@ -0,0 +1,26 @@
+-- Density (g/ml) untuk konversi antara satuan massa dan volume, misal susu atau minyak
+ALTER TABLE foods ADD COLUMN density numeric(10,4) CHECK (density > 0);
+
+-- Samakan serving_unit dengan simbol di pkg/converter. Satuan yang tidak dikenal sebelumnya
+-- dianggap gram, jadi diubah ke 'g' secara eksplisit.
+UPDATE foods SET serving_unit = lower(trim(serving_unit));
+
+UPDATE foods SET serving_unit = CASE
+    WHEN serving_unit IN ('µg', 'μg', 'ug', 'microgram', 'micrograms') THEN 'mcg'
+    WHEN serving_unit IN ('milligram', 'milligrams') THEN 'mg'
+    WHEN serving_unit IN ('kilogram', 'kilograms') THEN 'kg'
+    WHEN serving_unit IN ('ounce', 'ounces') THEN 'oz'
+    WHEN serving_unit IN ('lbs', 'pound', 'pounds') THEN 'lb'
+    WHEN serving_unit IN ('milliliter', 'milliliters', 'millilitre', 'millilitres') THEN 'ml'
+    WHEN serving_unit IN ('liter', 'liters', 'litre', 'litres') THEN 'l'
+    WHEN serving_unit IN ('teaspoon', 'teaspoons') THEN 'tsp'
+    WHEN serving_unit IN ('tablespoon', 'tablespoons') THEN 'tbsp'
+    WHEN serving_unit IN ('fl oz', 'floz', 'fluid ounce', 'fluid ounces') THEN 'fl_oz'
+    WHEN serving_unit = 'cups' THEN 'cup'
+    WHEN serving_unit IN ('pint', 'pints') THEN 'pt'
+    WHEN serving_unit IN ('quart', 'quarts') THEN 'qt'
+    WHEN serving_unit IN ('gallon', 'gallons') THEN 'gal'
+    WHEN serving_unit IN ('mcg', 'mg', 'g', 'kg', 'oz', 'lb', 'ml', 'l', 'tsp', 'tbsp', 'fl_oz', 'cup', 'pt', 'qt', 'gal', 'serving')
+        THEN serving_unit
+    ELSE 'g'
+END;
@@ -0,0 +1,4 @@
+ALTER TABLE food_diaries DROP COLUMN IF EXISTS food_revision_id;
+ALTER TABLE foods DROP COLUMN IF EXISTS revision;
+DROP TABLE IF EXISTS food_revision_nutrients;
+DROP TABLE IF EXISTS food_revisions;
@@ -0,0 +1,46 @@
+-- Snapshot nilai gizi food per perubahan. Barcode, nama lain dan portion tidak diversikan.
+CREATE TABLE IF NOT EXISTS food_revisions (
+    id bigserial PRIMARY KEY,
+    food_id bigint NOT NULL REFERENCES foods(id) ON DELETE CASCADE,
+    revision integer NOT NULL,
+    name varchar(255) NOT NULL,
+    description text,
+    serving_size numeric(10,2) NOT NULL,
+    serving_unit varchar(50) NOT NULL,
+    density numeric(10,4),
+    created_by bigint REFERENCES users(id) ON DELETE SET NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (food_id, revision)
+);
+
+CREATE TABLE IF NOT EXISTS food_revision_nutrients (
+    revision_id bigint NOT NULL REFERENCES food_revisions(id) ON DELETE CASCADE,
+    nutrient_id bigint NOT NULL REFERENCES nutrients(id) ON DELETE CASCADE,
+    amount numeric(10,2) NOT NULL,
+    PRIMARY KEY (revision_id, nutrient_id)
+);
+
+-- Nomor revisi yang sedang berlaku, 0 berarti belum ada revisi
+ALTER TABLE foods ADD COLUMN revision integer NOT NULL DEFAULT 0;
+
+-- Entry diary menyimpan revisi food saat dicatat supaya total harian lama tidak ikut berubah
+ALTER TABLE food_diaries ADD COLUMN food_revision_id bigint REFERENCES food_revisions(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_food_diaries_food_revision_id ON food_diaries (food_revision_id);
+
+-- Food yang sudah ada menjadi revisi 1, diary lama dipasangkan ke revisi tersebut
+INSERT INTO food_revisions (food_id, revision, name, description, serving_size, serving_unit, density, created_at)
+SELECT id, 1, name, description, serving_size, serving_unit, density, updated_at
+FROM foods;
+
+INSERT INTO food_revision_nutrients (revision_id, nutrient_id, amount)
+SELECT fr.id, fn.nutrient_id, fn.amount
+FROM food_revisions fr
+JOIN food_nutrients fn ON fn.food_id = fr.food_id;
+
+UPDATE foods SET revision = 1;
+
+UPDATE food_diaries fd
+SET food_revision_id = fr.id
+FROM food_revisions fr
+WHERE fr.food_id = fd.food_id AND fr.revision = 1;
@@ -0,0 +1,2 @@
+-- Food duplikat yang digabung di-soft-delete dan menunjuk ke food kanonik
+ALTER TABLE foods ADD COLUMN merged_into bigint REFERENCES foods(id) ON DELETE SET NULL;
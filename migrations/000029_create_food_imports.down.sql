@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS food_import_errors;
+DROP TABLE IF EXISTS food_imports;
+DROP INDEX IF EXISTS idx_foods_lower_name;
+DROP INDEX IF EXISTS idx_foods_external_id;
+ALTER TABLE foods DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,41 @@
+-- ID food di sumber data luar, dipakai import untuk upsert
+ALTER TABLE foods ADD COLUMN external_id varchar(100);
+
+CREATE UNIQUE INDEX idx_foods_external_id
+ON foods (external_id)
+WHERE external_id IS NOT NULL AND deleted_at IS NULL;
+
+-- Baris import tanpa external_id dicocokkan berdasarkan nama
+CREATE INDEX idx_foods_lower_name ON foods (lower(name)) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS food_imports (
+    id bigserial PRIMARY KEY,
+    created_by bigint REFERENCES users(id) ON DELETE SET NULL,
+    status varchar(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'processing', 'completed', 'failed')),
+    format varchar(10) NOT NULL CHECK (format IN ('csv', 'ndjson')),
+    dry_run boolean NOT NULL DEFAULT FALSE,
+    mapping jsonb NOT NULL DEFAULT '{}',
+    object_key varchar(255),
+    total_rows integer,
+    processed_rows integer NOT NULL DEFAULT 0,
+    created_rows integer NOT NULL DEFAULT 0,
+    updated_rows integer NOT NULL DEFAULT 0,
+    failed_rows integer NOT NULL DEFAULT 0,
+    last_error text,
+    attempts int NOT NULL DEFAULT 0,
+    locked_until timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    completed_at timestamp(0) with time zone
+);
+
+CREATE INDEX idx_food_imports_status ON food_imports (status, id);
+
+-- Satu baris file hanya punya satu error, baris pertama adalah 1 (tanpa header CSV)
+CREATE TABLE IF NOT EXISTS food_import_errors (
+    import_id bigint NOT NULL REFERENCES food_imports(id) ON DELETE CASCADE,
+    row_number integer NOT NULL,
+    key varchar(255),
+    message text NOT NULL,
+    PRIMARY KEY (import_id, row_number)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_email_outbox_pending;
+CREATE INDEX idx_email_outbox_pending ON email_outbox (send_after) WHERE sent_at IS NULL;
+
+ALTER TABLE email_outbox DROP COLUMN IF EXISTS failed_at;
@@ -0,0 +1,5 @@
+-- Email yang gagal terus sampai batas percobaan berhenti dikirim ulang
+ALTER TABLE email_outbox ADD COLUMN failed_at timestamp(0) with time zone;
+
+DROP INDEX IF EXISTS idx_email_outbox_pending;
+CREATE INDEX idx_email_outbox_pending ON email_outbox (send_after) WHERE sent_at IS NULL AND failed_at IS NULL;
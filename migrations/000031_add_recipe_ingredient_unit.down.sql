@@ -0,0 +1 @@
+ALTER TABLE recipe_ingredients DROP COLUMN IF EXISTS unit;
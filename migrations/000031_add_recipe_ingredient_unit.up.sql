@@ -0,0 +1,10 @@
+-- Quantity bahan disimpan bersama satuannya supaya resep tetap benar jika serving_unit
+-- bahan diubah. Bahan lama memakai serving_unit food saat ini.
+ALTER TABLE recipe_ingredients ADD COLUMN unit varchar(50);
+
+UPDATE recipe_ingredients ri
+SET unit = COALESCE(f.serving_unit, 'g')
+FROM foods f
+WHERE f.id = ri.food_id;
+
+ALTER TABLE recipe_ingredients ALTER COLUMN unit SET NOT NULL;
@@ -0,0 +1,63 @@
+// Package barcode memvalidasi dan menormalkan kode GTIN (EAN-8, UPC-A, EAN-13, GTIN-14)
+// yang tercetak di kemasan produk.
+package barcode
+
+import (
+	"errors"
+	"strings"
+)
+
+// Length adalah panjang GTIN setelah dinormalkan
+const Length = 14
+
+var (
+	ErrInvalidCharacter  = errors.New("barcode must contain digits only")
+	ErrInvalidLength     = errors.New("barcode must be 8, 12, 13 or 14 digits")
+	ErrInvalidCheckDigit = errors.New("barcode check digit is invalid")
+)
+
+// Normalize membersihkan spasi dan tanda hubung, memvalidasi check digit, lalu
+// mengembalikan GTIN-14 dengan nol di depan. Dengan begitu UPC-A "036000291452"
+// dan EAN-13 "0036000291452" dianggap barcode yang sama.
+func Normalize(code string) (string, error) {
+	code = strings.NewReplacer(" ", "", "-", "").Replace(code)
+
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return "", ErrInvalidCharacter
+		}
+	}
+
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return "", ErrInvalidLength
+	}
+
+	if CheckDigit(code[:len(code)-1]) != code[len(code)-1] {
+		return "", ErrInvalidCheckDigit
+	}
+
+	return strings.Repeat("0", Length-len(code)) + code, nil
+}
+
+// Valid mengecek apakah kode adalah GTIN yang valid
+func Valid(code string) bool {
+	_, err := Normalize(code)
+	return err == nil
+}
+
+// CheckDigit menghitung check digit GS1 (modulo 10) untuk digit tanpa check digit.
+// Dihitung dari kanan dengan bobot 3 dan 1 bergantian, jadi berlaku untuk semua panjang GTIN.
+func CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type Dimension string
+
+const (
+	Mass   Dimension = "mass"
+	Volume Dimension = "volume"
+)
+
+var (
+	ErrUnknownUnit      = errors.New("unknown unit")
+	ErrIncompatibleUnit = errors.New("incompatible units")
+)
+
+// Unit adalah satuan massa atau volume. Factor mengalikan jumlah ke satuan dasar
+// dimensinya: gram untuk massa, mililiter untuk volume.
+type Unit struct {
+	Symbol    string
+	Dimension Dimension
+	Factor    float64
+}
+
+// Satuan volume imperial memakai ukuran US customary
+var units = []Unit{
+	{"mcg", Mass, 0.000001},
+	{"mg", Mass, 0.001},
+	{"g", Mass, 1},
+	{"kg", Mass, 1000},
+	{"oz", Mass, 28.349523125},
+	{"lb", Mass, 453.59237},
+
+	{"ml", Volume, 1},
+	{"l", Volume, 1000},
+	{"tsp", Volume, 4.92892159375},
+	{"tbsp", Volume, 14.78676478125},
+	{"fl_oz", Volume, 29.5735295625},
+	{"cup", Volume, 236.5882365},
+	{"pt", Volume, 473.176473},
+	{"qt", Volume, 946.352946},
+	{"gal", Volume, 3785.411784},
+}
+
+var aliases = map[string]string{
+	"µg": "mcg", "μg": "mcg", "ug": "mcg", "microgram": "mcg", "micrograms": "mcg",
+	"milligram": "mg", "milligrams": "mg",
+	"gr": "g", "gram": "g", "grams": "g",
+	"kilogram": "kg", "kilograms": "kg",
+	"ounce": "oz", "ounces": "oz",
+	"lbs": "lb", "pound": "lb", "pounds": "lb",
+	"milliliter": "ml", "milliliters": "ml", "millilitre": "ml", "millilitres": "ml",
+	"liter": "l", "liters": "l", "litre": "l", "litres": "l",
+	"teaspoon": "tsp", "teaspoons": "tsp",
+	"tablespoon": "tbsp", "tablespoons": "tbsp",
+	"fl oz": "fl_oz", "floz": "fl_oz", "fluid ounce": "fl_oz", "fluid ounces": "fl_oz",
+	"cups": "cup",
+	"pint": "pt", "pints": "pt",
+	"quart": "qt", "quarts": "qt",
+	"gallon": "gal", "gallons": "gal",
+}
+
+var bySymbol = func() map[string]Unit {
+	m := make(map[string]Unit, len(units))
+	for _, u := range units {
+		m[u.Symbol] = u
+	}
+	return m
+}()
+
+// Units mengembalikan semua satuan yang dikenal
+func Units() []Unit {
+	return slices.Clone(units)
+}
+
+// ParseUnit mencari satuan berdasarkan simbol atau nama (tanpa membedakan huruf besar)
+func ParseUnit(s string) (Unit, error) {
+	key := strings.ToLower(strings.TrimSpace(s))
+	if symbol, ok := aliases[key]; ok {
+		key = symbol
+	}
+
+	u, ok := bySymbol[key]
+	if !ok {
+		return Unit{}, fmt.Errorf("%w: %q", ErrUnknownUnit, s)
+	}
+
+	return u, nil
+}
+
+// Convert mengonversi amount dari satuan from ke satuan to. density dalam g/ml dan hanya
+// dipakai untuk konversi antara massa dan volume, 0 berarti density tidak diketahui.
+func Convert(amount float64, from, to string, density float64) (float64, error) {
+	src, err := ParseUnit(from)
+	if err != nil {
+		return 0, err
+	}
+
+	dst, err := ParseUnit(to)
+	if err != nil {
+		return 0, err
+	}
+
+	base := amount * src.Factor
+
+	if src.Dimension != dst.Dimension {
+		if density <= 0 {
+			return 0, fmt.Errorf("%w: %s to %s requires a density", ErrIncompatibleUnit, src.Symbol, dst.Symbol)
+		}
+
+		if src.Dimension == Volume {
+			base *= density
+		} else {
+			base /= density
+		}
+	}
+
+	return base / dst.Factor, nil
+}
+
+// ToGrams mengonversi satuan massa atau volume (dengan density) ke gram
+func ToGrams(amount float64, unit string, density float64) (float64, error) {
+	return Convert(amount, unit, "g", density)
+}
+
+// FromGrams mengonversi gram ke satuan lain, kebalikan ToGrams
+func FromGrams(grams float64, unit string, density float64) (float64, error) {
+	return Convert(grams, "g", unit, density)
+}